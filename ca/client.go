@@ -22,8 +22,10 @@ func NewClient(options ...Option) *Client {
 
 	return &Client{
 		Client: rest.NewClient(rest.Config{
-			Client:  config.Client(),
-			BaseURL: config.BaseURL(),
+			Client:      config.Client(),
+			BaseURL:     config.BaseURL(),
+			RetryPolicy: config.RetryPolicy(),
+			Middlewares: config.Middlewares(),
 		}),
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/android-sms-gateway/client-go/ca"
+	"github.com/android-sms-gateway/client-go/rest"
 )
 
 func TestClient_PostCSR(t *testing.T) {
@@ -148,3 +149,33 @@ func TestClient_GetCSRStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_Middleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Trace-Id") != "trace" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"request_id":"123","status":"pending"}`))
+	}))
+	defer server.Close()
+
+	tracing := func(next rest.RoundTripFunc) rest.RoundTripFunc {
+		return func(ctx context.Context, req *rest.Request) (*rest.Response, error) {
+			req.Headers["X-Trace-Id"] = "trace"
+			return next(ctx, req)
+		}
+	}
+
+	client := ca.NewClient(ca.WithBaseURL(server.URL), ca.WithMiddleware(tracing))
+
+	got, err := client.GetCSRStatus(context.Background(), "123")
+	if err != nil {
+		t.Fatalf("Client.GetCSRStatus() unexpected error = %v", err)
+	}
+	if got.RequestID != "123" {
+		t.Errorf("expected request ID 123, got %q", got.RequestID)
+	}
+}
@@ -1,12 +1,18 @@
 package ca
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/android-sms-gateway/client-go/rest"
+)
 
 type Option func(*Config)
 
 type Config struct {
-	client  *http.Client // Optional HTTP Client, defaults to `http.DefaultClient`
-	baseURL string       // Optional base URL, defaults to `https://ca.sms-gate.app/api/v1`
+	client      *http.Client      // Optional HTTP Client, defaults to `http.DefaultClient`
+	baseURL     string            // Optional base URL, defaults to `https://ca.sms-gate.app/api/v1`
+	retryPolicy *rest.RetryPolicy // Optional retry policy, requests are not retried by default
+	middlewares []rest.Middleware // Optional middlewares, applied to every request in the given order
 }
 
 func (c Config) Client() *http.Client {
@@ -23,6 +29,14 @@ func (c Config) BaseURL() string {
 	return c.baseURL
 }
 
+func (c Config) RetryPolicy() *rest.RetryPolicy {
+	return c.retryPolicy
+}
+
+func (c Config) Middlewares() []rest.Middleware {
+	return c.middlewares
+}
+
 func WithClient(client *http.Client) Option {
 	return func(c *Config) {
 		c.client = client
@@ -34,3 +48,15 @@ func WithBaseURL(baseURL string) Option {
 		c.baseURL = baseURL
 	}
 }
+
+func WithRetryPolicy(policy *rest.RetryPolicy) Option {
+	return func(c *Config) {
+		c.retryPolicy = policy
+	}
+}
+
+func WithMiddleware(middlewares ...rest.Middleware) Option {
+	return func(c *Config) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
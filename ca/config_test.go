@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/android-sms-gateway/client-go/ca"
+	"github.com/android-sms-gateway/client-go/rest"
 )
 
 //nolint:gochecknoglobals // constant
@@ -68,3 +69,46 @@ func TestConfig_BaseURL(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_RetryPolicy(t *testing.T) {
+	policy := rest.DefaultRetryPolicy()
+
+	tests := []struct {
+		name   string
+		option ca.Option
+		want   *rest.RetryPolicy
+	}{
+		{
+			name:   "With Retry Policy",
+			option: ca.WithRetryPolicy(policy),
+			want:   policy,
+		},
+		{
+			name:   "Without Retry Policy",
+			option: ca.WithRetryPolicy(nil),
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := ca.Config{}
+			tt.option(&c)
+
+			if got := c.RetryPolicy(); got != tt.want {
+				t.Errorf("Config.RetryPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Middlewares(t *testing.T) {
+	noop := func(next rest.RoundTripFunc) rest.RoundTripFunc { return next }
+
+	c := ca.Config{}
+	ca.WithMiddleware(noop)(&c)
+	ca.WithMiddleware(noop, noop)(&c)
+
+	if got := len(c.Middlewares()); got != 3 {
+		t.Errorf("expected 3 middlewares, got %d", got)
+	}
+}
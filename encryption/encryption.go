@@ -0,0 +1,200 @@
+// Package encryption implements end-to-end encryption of messages compatible
+// with the Android app.
+//
+// Values are encrypted with AES-256-CBC using a key derived from the
+// passphrase with PBKDF2-HMAC-SHA1 and a random salt, which is also used as
+// the IV. They are encoded as
+//
+//	$aes-256-cbc/pbkdf2-sha1$i=<iterations>$<base64 salt>$<base64 ciphertext>
+//
+// The same passphrase must be set in `SettingsEncryption.Passphrase` on the
+// device.
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // required for compatibility with the app
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+const (
+	Scheme               = "aes-256-cbc/pbkdf2-sha1" // Encryption scheme used by the app
+	DefaultIterations    = 75_000                    // Default number of PBKDF2 iterations
+	DefaultMaxIterations = 10 * DefaultIterations    // Default upper bound of PBKDF2 iterations accepted by Decrypt
+
+	saltSize  = 16
+	keySize   = 32
+	fmtParts  = 5
+	iterParam = "i="
+)
+
+// Option configures an Encryptor.
+type Option func(*Encryptor)
+
+// WithIterations sets the number of PBKDF2 iterations used for encryption.
+// Decryption uses the number encoded in the value, see WithMaxIterations.
+// Defaults to DefaultIterations.
+func WithIterations(iterations int) Option {
+	return func(e *Encryptor) {
+		e.iterations = iterations
+	}
+}
+
+// WithMaxIterations sets the maximum number of PBKDF2 iterations accepted by
+// Decrypt, so values from untrusted sources can't block the caller for long.
+// Values with more iterations are rejected with ErrInvalidFormat, unless the
+// number doesn't exceed the one used for encryption. Defaults to
+// DefaultMaxIterations.
+func WithMaxIterations(iterations int) Option {
+	return func(e *Encryptor) {
+		e.maxIterations = iterations
+	}
+}
+
+// Encryptor encrypts and decrypts values with a passphrase. It implements
+// smsgateway.Cipher.
+type Encryptor struct {
+	passphrase    []byte
+	iterations    int
+	maxIterations int
+	random        io.Reader
+}
+
+var _ smsgateway.Cipher = (*Encryptor)(nil)
+
+// New creates an Encryptor for the passphrase.
+func New(passphrase string, options ...Option) *Encryptor {
+	e := &Encryptor{
+		passphrase:    []byte(passphrase),
+		iterations:    DefaultIterations,
+		maxIterations: DefaultMaxIterations,
+		random:        rand.Reader,
+	}
+
+	for _, option := range options {
+		option(e)
+	}
+
+	return e
+}
+
+// Encrypt encrypts the plaintext with a random salt.
+func (e *Encryptor) Encrypt(plaintext string) (string, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(e.random, salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	block, err := aes.NewCipher(e.key(salt, e.iterations))
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	data := pad([]byte(plaintext), block.BlockSize())
+	cipher.NewCBCEncrypter(block, salt).CryptBlocks(data, data)
+
+	return fmt.Sprintf(
+		"$%s$%s%d$%s$%s",
+		Scheme,
+		iterParam, e.iterations,
+		base64.StdEncoding.EncodeToString(salt),
+		base64.StdEncoding.EncodeToString(data),
+	), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt or by the app.
+func (e *Encryptor) Decrypt(ciphertext string) (string, error) {
+	parts := strings.Split(ciphertext, "$")
+	if len(parts) != fmtParts || parts[0] != "" {
+		return "", ErrInvalidFormat
+	}
+	if parts[1] != Scheme {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedScheme, parts[1])
+	}
+
+	iterations, err := strconv.Atoi(strings.TrimPrefix(parts[2], iterParam))
+	if err != nil || !strings.HasPrefix(parts[2], iterParam) || iterations < 1 {
+		return "", fmt.Errorf("%w: invalid iterations %q", ErrInvalidFormat, parts[2])
+	}
+	if iterations > max(e.maxIterations, e.iterations) {
+		return "", fmt.Errorf("%w: too many iterations %d", ErrInvalidFormat, iterations)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil || len(salt) != saltSize {
+		return "", fmt.Errorf("%w: invalid salt", ErrInvalidFormat)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(parts[4])
+	if err != nil || len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return "", fmt.Errorf("%w: invalid ciphertext", ErrInvalidFormat)
+	}
+
+	block, err := aes.NewCipher(e.key(salt, iterations))
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	cipher.NewCBCDecrypter(block, salt).CryptBlocks(data, data)
+
+	plaintext, ok := unpad(data, block.BlockSize())
+	if !ok {
+		return "", ErrDecryptionFailed
+	}
+	return string(plaintext), nil
+}
+
+// EncryptMessage returns a copy of the message with its content and phone
+// numbers encrypted.
+func (e *Encryptor) EncryptMessage(message smsgateway.Message) (smsgateway.Message, error) {
+	return message.Encrypt(e) //nolint:wrapcheck // already wrapped
+}
+
+// DecryptMessageState returns a copy of the message state with its content
+// and phone numbers decrypted.
+func (e *Encryptor) DecryptMessageState(state smsgateway.MessageState) (smsgateway.MessageState, error) {
+	return state.Decrypt(e) //nolint:wrapcheck // already wrapped
+}
+
+// DecryptIncomingMessage returns a copy of the incoming message with its
+// encrypted fields decrypted.
+func (e *Encryptor) DecryptIncomingMessage(message smsgateway.IncomingMessage) (smsgateway.IncomingMessage, error) {
+	return message.Decrypt(e) //nolint:wrapcheck // already wrapped
+}
+
+func (e *Encryptor) key(salt []byte, iterations int) []byte {
+	return pbkdf2(e.passphrase, salt, iterations, keySize, sha1.New)
+}
+
+// pad applies PKCS#7 padding.
+func pad(data []byte, blockSize int) []byte {
+	n := blockSize - len(data)%blockSize
+	return append(data, bytes.Repeat([]byte{byte(n)}, n)...)
+}
+
+// unpad removes PKCS#7 padding.
+func unpad(data []byte, blockSize int) ([]byte, bool) {
+	if len(data) == 0 {
+		return nil, false
+	}
+
+	n := int(data[len(data)-1])
+	if n == 0 || n > blockSize || n > len(data) {
+		return nil, false
+	}
+	for _, b := range data[len(data)-n:] {
+		if int(b) != n {
+			return nil, false
+		}
+	}
+	return data[:len(data)-n], true
+}
@@ -0,0 +1,225 @@
+package encryption_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/android-sms-gateway/client-go/encryption"
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+const (
+	passphrase = "passphrase"
+	// Encrypted with `openssl enc -aes-256-cbc`, using the PBKDF2-SHA1 key and the salt as IV.
+	knownCiphertext = "$aes-256-cbc/pbkdf2-sha1$i=75000$AAECAwQFBgcICQoLDA0ODw==$J4jHMyiKRXTEhQK8q2RXyw=="
+	knownPlaintext  = "+79990001234"
+)
+
+func TestEncryptor_Decrypt(t *testing.T) {
+	tests := []struct {
+		name       string
+		ciphertext string
+		passphrase string
+		expected   string
+		expectErr  error
+	}{
+		{
+			name:       "Known value",
+			ciphertext: knownCiphertext,
+			passphrase: passphrase,
+			expected:   knownPlaintext,
+		},
+		{
+			name:       "Wrong passphrase",
+			ciphertext: knownCiphertext,
+			passphrase: "wrong",
+			expectErr:  encryption.ErrDecryptionFailed,
+		},
+		{
+			name:       "Unsupported scheme",
+			ciphertext: "$aes-128-gcm$i=1$AAECAwQFBgcICQoLDA0ODw==$J4jHMyiKRXTEhQK8q2RXyw==",
+			passphrase: passphrase,
+			expectErr:  encryption.ErrUnsupportedScheme,
+		},
+		{
+			name:       "Plain text",
+			ciphertext: knownPlaintext,
+			passphrase: passphrase,
+			expectErr:  encryption.ErrInvalidFormat,
+		},
+		{
+			name:       "Invalid iterations",
+			ciphertext: "$aes-256-cbc/pbkdf2-sha1$n=75000$AAECAwQFBgcICQoLDA0ODw==$J4jHMyiKRXTEhQK8q2RXyw==",
+			passphrase: passphrase,
+			expectErr:  encryption.ErrInvalidFormat,
+		},
+		{
+			name:       "Too many iterations",
+			ciphertext: "$aes-256-cbc/pbkdf2-sha1$i=2000000000$AAECAwQFBgcICQoLDA0ODw==$J4jHMyiKRXTEhQK8q2RXyw==",
+			passphrase: passphrase,
+			expectErr:  encryption.ErrInvalidFormat,
+		},
+		{
+			name:       "Short salt",
+			ciphertext: "$aes-256-cbc/pbkdf2-sha1$i=75000$AAEC$J4jHMyiKRXTEhQK8q2RXyw==",
+			passphrase: passphrase,
+			expectErr:  encryption.ErrInvalidFormat,
+		},
+		{
+			name:       "Truncated ciphertext",
+			ciphertext: "$aes-256-cbc/pbkdf2-sha1$i=75000$AAECAwQFBgcICQoLDA0ODw==$J4jHMyiKRXTE",
+			passphrase: passphrase,
+			expectErr:  encryption.ErrInvalidFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encryption.New(tt.passphrase).Decrypt(tt.ciphertext)
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("Decrypt() error = %v, want %v", err, tt.expectErr)
+			}
+			if got != tt.expected {
+				t.Errorf("Decrypt() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEncryptor_MaxIterations(t *testing.T) {
+	limited := encryption.New(passphrase, encryption.WithIterations(1000), encryption.WithMaxIterations(1000))
+	if _, err := limited.Decrypt(knownCiphertext); !errors.Is(err, encryption.ErrInvalidFormat) {
+		t.Errorf("Decrypt() error = %v, want %v", err, encryption.ErrInvalidFormat)
+	}
+
+	// Values encrypted with the own number of iterations are always accepted.
+	own := encryption.New(passphrase, encryption.WithIterations(encryption.DefaultIterations), encryption.WithMaxIterations(1000))
+	if got, err := own.Decrypt(knownCiphertext); err != nil || got != knownPlaintext {
+		t.Errorf("Decrypt() = %q, %v, want %q", got, err, knownPlaintext)
+	}
+}
+
+func TestEncryptor_RoundTrip(t *testing.T) {
+	e := encryption.New(passphrase, encryption.WithIterations(1000))
+
+	for _, plaintext := range []string{"", "Hello", "exactly 16 bytes", "Привет, мир! 👋"} {
+		ciphertext, err := e.Encrypt(plaintext)
+		if err != nil {
+			t.Fatalf("Encrypt() unexpected error = %v", err)
+		}
+		if !strings.HasPrefix(ciphertext, "$aes-256-cbc/pbkdf2-sha1$i=1000$") {
+			t.Errorf("unexpected format: %s", ciphertext)
+		}
+
+		// Decryption uses the iterations from the value.
+		decrypted, err := encryption.New(passphrase).Decrypt(ciphertext)
+		if err != nil {
+			t.Fatalf("Decrypt() unexpected error = %v", err)
+		}
+		if decrypted != plaintext {
+			t.Errorf("Decrypt() = %q, want %q", decrypted, plaintext)
+		}
+	}
+
+	first, _ := e.Encrypt("Hello")
+	second, _ := e.Encrypt("Hello")
+	if first == second {
+		t.Error("expected random salt for every value")
+	}
+}
+
+func TestEncryptor_Messages(t *testing.T) {
+	e := encryption.New(passphrase, encryption.WithIterations(1000))
+
+	message := smsgateway.Message{
+		TextMessage:  &smsgateway.TextMessage{Text: "Hello"},
+		PhoneNumbers: []string{"+79990001234", "+79990001235"},
+	}
+	encrypted, err := e.EncryptMessage(message)
+	if err != nil {
+		t.Fatalf("EncryptMessage() unexpected error = %v", err)
+	}
+	if !encrypted.IsEncrypted || encrypted.TextMessage.Text == "Hello" || encrypted.PhoneNumbers[0] == "+79990001234" {
+		t.Errorf("expected message to be encrypted, got %+v", encrypted)
+	}
+	if message.TextMessage.Text != "Hello" || message.PhoneNumbers[0] != "+79990001234" {
+		t.Error("original message must not be modified")
+	}
+
+	again, _ := e.EncryptMessage(encrypted)
+	if again.TextMessage.Text != encrypted.TextMessage.Text {
+		t.Error("encrypted messages must not be encrypted twice")
+	}
+
+	state, err := e.DecryptMessageState(smsgateway.MessageState{
+		IsEncrypted: true,
+		TextMessage: encrypted.TextMessage,
+		Recipients: []smsgateway.RecipientState{
+			{PhoneNumber: encrypted.PhoneNumbers[0]},
+			{PhoneNumber: "hashed0123456789"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("DecryptMessageState() unexpected error = %v", err)
+	}
+	if state.IsEncrypted || state.TextMessage.Text != "Hello" ||
+		state.Recipients[0].PhoneNumber != "+79990001234" || state.Recipients[1].PhoneNumber != "hashed0123456789" {
+		t.Errorf("unexpected decrypted state: %+v", state)
+	}
+
+	incoming, err := e.DecryptIncomingMessage(smsgateway.IncomingMessage{
+		Sender:         knownCiphertext,
+		ContentPreview: encrypted.TextMessage.Text,
+	})
+	if err != nil {
+		t.Fatalf("DecryptIncomingMessage() unexpected error = %v", err)
+	}
+	if incoming.Sender != knownPlaintext || incoming.ContentPreview != "Hello" {
+		t.Errorf("unexpected decrypted message: %+v", incoming)
+	}
+}
+
+func TestClient_WithEncryption(t *testing.T) {
+	e := encryption.New(passphrase, encryption.WithIterations(1000))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		message := smsgateway.Message{}
+		_ = json.NewDecoder(r.Body).Decode(&message)
+
+		if !message.IsEncrypted || message.TextMessage == nil || message.TextMessage.Text == "Hello" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(smsgateway.MessageState{
+			ID:          "123",
+			State:       smsgateway.ProcessingStatePending,
+			IsEncrypted: true,
+			Recipients:  []smsgateway.RecipientState{{PhoneNumber: message.PhoneNumbers[0]}},
+		})
+	}))
+	defer server.Close()
+
+	client := smsgateway.NewClient(smsgateway.Config{
+		BaseURL:  server.URL,
+		User:     "user",
+		Password: "pass",
+	}.WithEncryption(e))
+
+	state, err := client.Send(context.Background(), smsgateway.Message{
+		TextMessage:  &smsgateway.TextMessage{Text: "Hello"},
+		PhoneNumbers: []string{"+79990001234"},
+	})
+	if err != nil {
+		t.Fatalf("Send() unexpected error = %v", err)
+	}
+	if state.Recipients[0].PhoneNumber != "+79990001234" {
+		t.Errorf("expected decrypted phone number, got %s", state.Recipients[0].PhoneNumber)
+	}
+}
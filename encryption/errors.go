@@ -0,0 +1,9 @@
+package encryption
+
+import "errors"
+
+var (
+	ErrInvalidFormat     = errors.New("invalid encrypted format")
+	ErrUnsupportedScheme = errors.New("unsupported encryption scheme")
+	ErrDecryptionFailed  = errors.New("decryption failed")
+)
@@ -0,0 +1,10 @@
+package phone
+
+import "errors"
+
+var (
+	ErrInvalidNumber   = errors.New("invalid phone number")
+	ErrUnknownRegion   = errors.New("unknown region")
+	ErrNoValidNumbers  = errors.New("no valid phone numbers")
+	ErrShortCodeDenied = errors.New("short codes are not allowed")
+)
@@ -0,0 +1,254 @@
+// Package phone normalizes phone numbers to the E.164 format before sending
+// messages.
+//
+// The package doesn't use full numbering plan metadata: it strips formatting,
+// resolves national numbers for a default region, and rejects numbers that are
+// obviously invalid, such as too short or too long ones.
+package phone
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+const (
+	minE164Length      = 7  // minimum number of digits of a number in E.164 format
+	maxE164Length      = 15 // maximum number of digits of a number in E.164 format
+	minShortCodeLength = 3  // minimum number of digits of a short code
+	maxShortCodeLength = 6  // maximum number of digits of a short code
+
+	genericInternationalPrefix = "00"
+)
+
+// Option configures a Normalizer.
+type Option func(*Normalizer)
+
+// WithShortCodes allows short codes, e.g. `900`, which are kept as is.
+// Short codes are rejected by default.
+func WithShortCodes(allowed bool) Option {
+	return func(n *Normalizer) {
+		n.shortCodes = allowed
+	}
+}
+
+// Normalizer converts phone numbers to the E.164 format.
+type Normalizer struct {
+	region     *region
+	shortCodes bool
+}
+
+// New creates a Normalizer that resolves national numbers for the default
+// region, an ISO 3166-1 alpha-2 code like `US`. With an empty region, only
+// numbers in international format are accepted.
+func New(defaultRegion string, options ...Option) (*Normalizer, error) {
+	n := &Normalizer{region: nil, shortCodes: false}
+
+	if defaultRegion != "" {
+		r, ok := regions[strings.ToUpper(defaultRegion)]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownRegion, defaultRegion)
+		}
+		n.region = &r
+	}
+
+	for _, option := range options {
+		option(n)
+	}
+
+	return n, nil
+}
+
+// Normalize returns the number in E.164 format, e.g. `+12025550123`, or the
+// short code as is if short codes are allowed.
+func (n *Normalizer) Normalize(number string) (string, error) {
+	digits, international, err := strip(number)
+	if err != nil {
+		return "", err
+	}
+
+	if !international {
+		prefix := genericInternationalPrefix
+		if n.region != nil {
+			prefix = n.region.internationalPrefix
+		}
+		if digits, international = strings.CutPrefix(digits, prefix); !international {
+			return n.normalizeNational(number, digits)
+		}
+	}
+
+	if r, ok := n.regionOf(digits); ok {
+		// Drop the trunk prefix kept after the country code, e.g. `+44 (0) 20`.
+		nsn := strings.TrimPrefix(digits[len(r.countryCode):], r.internationalTrunk())
+		if !r.validNSN(nsn) {
+			return "", fmt.Errorf("%w: %q is not a valid number", ErrInvalidNumber, number)
+		}
+		digits = r.countryCode + nsn
+	}
+	if len(digits) < minE164Length || len(digits) > maxE164Length || digits[0] == '0' {
+		return "", fmt.Errorf("%w: %q has invalid length", ErrInvalidNumber, number)
+	}
+
+	return "+" + digits, nil
+}
+
+// IsShortCode reports whether the number looks like a short code.
+func IsShortCode(number string) bool {
+	digits, international, err := strip(number)
+	return err == nil && !international && isShortCode(digits)
+}
+
+func (n *Normalizer) normalizeNational(number, digits string) (string, error) {
+	if isShortCode(digits) {
+		if !n.shortCodes {
+			return "", fmt.Errorf("%w: %q", ErrShortCodeDenied, number)
+		}
+		return digits, nil
+	}
+
+	r := n.region
+	if r == nil {
+		return "", fmt.Errorf("%w: %q is not in international format", ErrInvalidNumber, number)
+	}
+
+	nsn := digits
+	if trunk, ok := strings.CutPrefix(digits, r.trunkPrefix); ok && r.trunkPrefix != "" {
+		nsn = trunk
+	} else if national, hasCode := strings.CutPrefix(digits, r.countryCode); hasCode && r.validLength(national) {
+		// International format without the plus sign.
+		nsn = national
+	}
+
+	if !r.validNSN(nsn) {
+		return "", fmt.Errorf("%w: %q is not a valid number", ErrInvalidNumber, number)
+	}
+
+	return "+" + r.countryCode + nsn, nil
+}
+
+// regionOf returns the region of the number in international format, without
+// the plus sign, preferring the default region. Regions sharing a country
+// code share the numbering plan, so any of them will do.
+func (n *Normalizer) regionOf(digits string) (region, bool) {
+	if n.region != nil && strings.HasPrefix(digits, n.region.countryCode) {
+		return *n.region, true
+	}
+
+	var (
+		found region
+		ok    bool
+	)
+	for _, r := range regions {
+		if strings.HasPrefix(digits, r.countryCode) && (!ok || len(r.countryCode) > len(found.countryCode)) {
+			found, ok = r, true
+		}
+	}
+	return found, ok
+}
+
+// internationalTrunk returns the trunk prefix that may be mistakenly kept
+// after the country code. Only a `0` trunk prefix can't be mistaken for the
+// start of the national significant number.
+func (r region) internationalTrunk() string {
+	if r.trunkPrefix == "0" {
+		return r.trunkPrefix
+	}
+	return ""
+}
+
+// validNSN reports whether the national significant number has a valid length
+// and doesn't start with a `0` trunk prefix.
+func (r region) validNSN(nsn string) bool {
+	if r.trunkPrefix == "0" && strings.HasPrefix(nsn, "0") {
+		return false
+	}
+	return r.validLength(nsn)
+}
+
+func (r region) validLength(nsn string) bool {
+	return len(nsn) >= r.minLength && len(nsn) <= r.maxLength
+}
+
+// strip removes formatting characters and reports whether the number starts
+// with a plus sign.
+func strip(number string) (string, bool, error) {
+	number = strings.TrimSpace(number)
+	international := strings.HasPrefix(number, "+")
+	number = strings.TrimPrefix(number, "+")
+
+	digits := make([]byte, 0, len(number))
+	for _, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, byte(r))
+		case strings.ContainsRune(" -.()/\t ", r):
+		default:
+			return "", false, fmt.Errorf("%w: unexpected character %q", ErrInvalidNumber, r)
+		}
+	}
+
+	if len(digits) == 0 {
+		return "", false, fmt.Errorf("%w: empty", ErrInvalidNumber)
+	}
+	return string(digits), international, nil
+}
+
+func isShortCode(digits string) bool {
+	return len(digits) >= minShortCodeLength && len(digits) <= maxShortCodeLength
+}
+
+// Rejection is a phone number rejected by NormalizeAll.
+type Rejection struct {
+	Number string // Original number
+	Err    error  // Reason
+}
+
+// NormalizeAll normalizes the numbers and removes duplicates, keeping the
+// order of first occurrence. Invalid numbers are reported as rejections.
+func (n *Normalizer) NormalizeAll(numbers []string) ([]string, []Rejection) {
+	seen := make(map[string]struct{}, len(numbers))
+	valid := make([]string, 0, len(numbers))
+	var rejected []Rejection
+
+	for _, number := range numbers {
+		normalized, err := n.Normalize(number)
+		if err != nil {
+			rejected = append(rejected, Rejection{Number: number, Err: err})
+			continue
+		}
+
+		if _, ok := seen[normalized]; ok {
+			continue
+		}
+		seen[normalized] = struct{}{}
+		valid = append(valid, normalized)
+	}
+
+	return valid, rejected
+}
+
+// Report lists the numbers rejected by a SendOption.
+type Report struct {
+	Rejected []Rejection
+}
+
+// SendOption returns a smsgateway.SendOption that normalizes and dedupes the
+// recipients of a message before it is sent. Rejected numbers are dropped and
+// listed in the report, if not nil. Sending fails with ErrNoValidNumbers if no
+// valid numbers remain.
+//
+// A report must not be shared between concurrent Send calls.
+func (n *Normalizer) SendOption(report *Report) smsgateway.SendOption {
+	return smsgateway.WithRecipientsRewriter(func(numbers []string) ([]string, error) {
+		valid, rejected := n.NormalizeAll(numbers)
+		if report != nil {
+			report.Rejected = rejected
+		}
+
+		if len(valid) == 0 {
+			return nil, ErrNoValidNumbers
+		}
+		return valid, nil
+	})
+}
@@ -0,0 +1,188 @@
+package phone_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/android-sms-gateway/client-go/phone"
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		region    string
+		expectErr error
+	}{
+		{name: "No region", region: ""},
+		{name: "Known region", region: "US"},
+		{name: "Lower case region", region: "gb"},
+		{name: "Unknown region", region: "XX", expectErr: phone.ErrUnknownRegion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := phone.New(tt.region)
+			if !errors.Is(err, tt.expectErr) {
+				t.Errorf("New() error = %v, expected %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestNormalizer_Normalize(t *testing.T) {
+	tests := []struct {
+		name       string
+		region     string
+		shortCodes bool
+		number     string
+		expected   string
+		expectErr  error
+	}{
+		{name: "International", region: "US", number: "+1 (202) 555-0123", expected: "+12025550123"},
+		{name: "National", region: "US", number: "(202) 555-0123", expected: "+12025550123"},
+		{name: "National with trunk prefix", region: "RU", number: "8 (999) 000-12-34", expected: "+79990001234"},
+		{name: "Country code without plus", region: "RU", number: "79990001234", expected: "+79990001234"},
+		{name: "Generic international prefix", region: "DE", number: "00 44 20 7946 0018", expected: "+442079460018"},
+		{name: "Region international prefix", region: "US", number: "011 44 20 7946 0018", expected: "+442079460018"},
+		{name: "Trunk prefix in international", region: "GB", number: "+44 (0) 20 7946 0018", expected: "+442079460018"},
+		{name: "Foreign number", region: "GB", number: "+1.202.555.0123", expected: "+12025550123"},
+		{name: "Trunk prefix in international of region", region: "DE", number: "+49 (0) 30 123456", expected: "+4930123456"},
+		{name: "Trunk prefix in foreign number", region: "DE", number: "+44 (0) 20 7946 0018", expected: "+442079460018"},
+		{name: "Trunk prefix without region", number: "+44 (0) 20 7946 0018", expected: "+442079460018"},
+		{name: "Double trunk prefix in international", region: "DE", number: "+49 00 30 123456", expectErr: phone.ErrInvalidNumber},
+		{name: "National with trunk prefix of any length", region: "DE", number: "030 123456", expected: "+4930123456"},
+		{name: "National too short without trunk prefix", region: "GB", number: "030 123456", expectErr: phone.ErrInvalidNumber},
+		{name: "No region international", number: "+79990001234", expected: "+79990001234"},
+		{name: "No region national", number: "9990001234", expectErr: phone.ErrInvalidNumber},
+		{name: "Letters", region: "US", number: "1-800-FLOWERS", expectErr: phone.ErrInvalidNumber},
+		{name: "Empty", region: "US", number: " ", expectErr: phone.ErrInvalidNumber},
+		{name: "Too short national", region: "US", number: "555-0123", expectErr: phone.ErrInvalidNumber},
+		{name: "Too long international", region: "US", number: "+1234567890123456", expectErr: phone.ErrInvalidNumber},
+		{name: "Invalid length in region", region: "RU", number: "+7999000123", expectErr: phone.ErrInvalidNumber},
+		{name: "Leading zero country code", number: "+0123456789", expectErr: phone.ErrInvalidNumber},
+		{name: "Short code denied", region: "US", number: "90 0", expectErr: phone.ErrShortCodeDenied},
+		{name: "Short code allowed", region: "US", shortCodes: true, number: "900", expected: "900"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := phone.New(tt.region, phone.WithShortCodes(tt.shortCodes))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			actual, err := n.Normalize(tt.number)
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("Normalize() error = %v, expected %v", err, tt.expectErr)
+			}
+			if actual != tt.expected {
+				t.Errorf("Normalize() = %q, expected %q", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizer_NormalizeAll(t *testing.T) {
+	n, err := phone.New("RU")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	valid, rejected := n.NormalizeAll([]string{"+7 999 000-12-34", "invalid", "89990001234", "+79990005678"})
+
+	expected := []string{"+79990001234", "+79990005678"}
+	if !reflect.DeepEqual(valid, expected) {
+		t.Errorf("NormalizeAll() valid = %v, expected %v", valid, expected)
+	}
+	if len(rejected) != 1 || rejected[0].Number != "invalid" || !errors.Is(rejected[0].Err, phone.ErrInvalidNumber) {
+		t.Errorf("NormalizeAll() rejected = %v", rejected)
+	}
+}
+
+func TestIsShortCode(t *testing.T) {
+	for number, expected := range map[string]bool{
+		"900":          true,
+		"12 34 56":     true,
+		"+900":         false,
+		"12":           false,
+		"9990001234":   false,
+		"not a number": false,
+	} {
+		if actual := phone.IsShortCode(number); actual != expected {
+			t.Errorf("IsShortCode(%q) = %v, expected %v", number, actual, expected)
+		}
+	}
+}
+
+func TestNormalizer_SendOption(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		message := smsgateway.Message{}
+		_ = json.NewDecoder(r.Body).Decode(&message)
+		received = message.PhoneNumbers
+
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(smsgateway.MessageState{ID: "123", State: smsgateway.ProcessingStatePending})
+	}))
+	defer server.Close()
+
+	client := smsgateway.NewClient(smsgateway.Config{
+		BaseURL:  server.URL,
+		User:     "user",
+		Password: "pass",
+	})
+
+	n, err := phone.New("US")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		phoneNumbers []string
+		expected     []string
+		rejected     int
+		expectErr    error
+	}{
+		{
+			name:         "Rewrites and dedupes",
+			phoneNumbers: []string{"(202) 555-0123", "+1 202 555 0123", "12", "+44 20 7946 0018"},
+			expected:     []string{"+12025550123", "+442079460018"},
+			rejected:     1,
+		},
+		{
+			name:         "No valid numbers",
+			phoneNumbers: []string{"12", "900"},
+			rejected:     2,
+			expectErr:    phone.ErrNoValidNumbers,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+			report := phone.Report{}
+
+			_, err := client.Send(context.Background(), smsgateway.Message{
+				TextMessage:  &smsgateway.TextMessage{Text: "Hello"},
+				PhoneNumbers: tt.phoneNumbers,
+			}, n.SendOption(&report))
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("Send() error = %v, expected %v", err, tt.expectErr)
+			}
+
+			if !reflect.DeepEqual(received, tt.expected) {
+				t.Errorf("Send() recipients = %v, expected %v", received, tt.expected)
+			}
+			if len(report.Rejected) != tt.rejected {
+				t.Errorf("Send() rejected = %v, expected %d", report.Rejected, tt.rejected)
+			}
+		})
+	}
+}
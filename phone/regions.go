@@ -0,0 +1,37 @@
+package phone
+
+// region describes the dialing plan of a region.
+type region struct {
+	countryCode         string // country calling code
+	trunkPrefix         string // prefix of national numbers, stripped in E.164
+	internationalPrefix string // prefix dialed before the country code
+	minLength           int    // minimum length of the national significant number
+	maxLength           int    // maximum length of the national significant number
+}
+
+// regions maps ISO 3166-1 alpha-2 codes to dialing plans. It covers common
+// regions only, numbers from other regions must be in international format.
+//
+//nolint:gochecknoglobals,mnd // lookup table
+var regions = map[string]region{
+	"AU": {countryCode: "61", trunkPrefix: "0", internationalPrefix: "0011", minLength: 9, maxLength: 9},
+	"BR": {countryCode: "55", trunkPrefix: "0", internationalPrefix: "00", minLength: 10, maxLength: 11},
+	"CA": {countryCode: "1", trunkPrefix: "1", internationalPrefix: "011", minLength: 10, maxLength: 10},
+	"CN": {countryCode: "86", trunkPrefix: "0", internationalPrefix: "00", minLength: 8, maxLength: 11},
+	"DE": {countryCode: "49", trunkPrefix: "0", internationalPrefix: "00", minLength: 6, maxLength: 13},
+	"ES": {countryCode: "34", trunkPrefix: "", internationalPrefix: "00", minLength: 9, maxLength: 9},
+	"FR": {countryCode: "33", trunkPrefix: "0", internationalPrefix: "00", minLength: 9, maxLength: 9},
+	"GB": {countryCode: "44", trunkPrefix: "0", internationalPrefix: "00", minLength: 9, maxLength: 10},
+	"ID": {countryCode: "62", trunkPrefix: "0", internationalPrefix: "00", minLength: 8, maxLength: 12},
+	"IN": {countryCode: "91", trunkPrefix: "0", internationalPrefix: "00", minLength: 10, maxLength: 10},
+	"IT": {countryCode: "39", trunkPrefix: "", internationalPrefix: "00", minLength: 6, maxLength: 11},
+	"JP": {countryCode: "81", trunkPrefix: "0", internationalPrefix: "010", minLength: 9, maxLength: 10},
+	"KZ": {countryCode: "7", trunkPrefix: "8", internationalPrefix: "810", minLength: 10, maxLength: 10},
+	"MX": {countryCode: "52", trunkPrefix: "", internationalPrefix: "00", minLength: 10, maxLength: 10},
+	"NL": {countryCode: "31", trunkPrefix: "0", internationalPrefix: "00", minLength: 9, maxLength: 9},
+	"PL": {countryCode: "48", trunkPrefix: "", internationalPrefix: "00", minLength: 9, maxLength: 9},
+	"RU": {countryCode: "7", trunkPrefix: "8", internationalPrefix: "810", minLength: 10, maxLength: 10},
+	"TR": {countryCode: "90", trunkPrefix: "0", internationalPrefix: "00", minLength: 10, maxLength: 10},
+	"UA": {countryCode: "380", trunkPrefix: "0", internationalPrefix: "00", minLength: 9, maxLength: 9},
+	"US": {countryCode: "1", trunkPrefix: "1", internationalPrefix: "011", minLength: 10, maxLength: 10},
+}
@@ -1,21 +1,28 @@
 package rest
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
-	"strings"
+	"time"
 )
 
 type Config struct {
-	Client  *http.Client // Optional HTTP Client, defaults to `http.DefaultClient`
-	BaseURL string       // Optional base URL
+	Client      *http.Client // Optional HTTP Client, defaults to `http.DefaultClient`
+	BaseURL     string       // Optional base URL
+	RetryPolicy *RetryPolicy // Optional retry policy, requests are not retried by default
+	Middlewares []Middleware // Optional middlewares, applied to every attempt in the given order
+
+	RequestEditors []RequestEditorFunc // Optional editors, applied to every HTTP request right before it is sent
 }
 
 type Client struct {
-	config Config
+	config  Config
+	handler RoundTripFunc
 }
 
 func NewClient(config Config) *Client {
@@ -23,7 +30,10 @@ func NewClient(config Config) *Client {
 		config.Client = http.DefaultClient
 	}
 
-	return &Client{config: config}
+	c := &Client{config: config, handler: nil}
+	c.handler = chain(config.Middlewares)(c.roundTrip)
+
+	return c
 }
 
 func (c *Client) Do(ctx context.Context, method, path string, headers map[string]string, payload, response any) error {
@@ -47,16 +57,49 @@ func (c *Client) do(
 	headers map[string]string,
 	payload, response any,
 ) (http.Header, error) {
+	policy := c.config.RetryPolicy
+	idempotent := isIdempotent(ctx, method)
+	for attempt := 1; ; attempt++ {
+		req := &Request{
+			Method:   method,
+			Path:     path,
+			Headers:  cloneHeaders(headers),
+			Payload:  payload,
+			Response: response,
+		}
+
+		var (
+			header     http.Header
+			statusCode int
+		)
+		resp, err := c.handler(ctx, req)
+		if resp != nil {
+			header, statusCode = resp.Header, resp.StatusCode
+		}
+
+		if !policy.shouldRetry(idempotent, attempt, statusCode, err) {
+			return header, err
+		}
+
+		if sleepErr := sleep(ctx, policy.delay(attempt, err)); sleepErr != nil {
+			return header, err
+		}
+	}
+}
+
+// roundTrip performs a single HTTP request. It is the innermost RoundTripFunc
+// of the middleware chain.
+func (c *Client) roundTrip(ctx context.Context, r *Request) (*Response, error) {
 	var reqBody io.Reader
-	if payload != nil {
-		jsonBytes, err := json.Marshal(payload)
+	if r.Payload != nil {
+		jsonBytes, err := json.Marshal(r.Payload)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal payload: %w", err)
 		}
-		reqBody = strings.NewReader(string(jsonBytes))
+		reqBody = bytes.NewReader(jsonBytes)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, c.config.BaseURL+path, reqBody)
+	req, err := http.NewRequestWithContext(ctx, r.Method, c.config.BaseURL+r.Path, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -64,9 +107,14 @@ func (c *Client) do(
 	if reqBody != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
-	for k, v := range headers {
+	for k, v := range r.Headers {
 		req.Header.Set(k, v)
 	}
+	for _, edit := range c.config.RequestEditors {
+		if editErr := edit(ctx, req); editErr != nil {
+			return nil, fmt.Errorf("failed to edit request: %w", editErr)
+		}
+	}
 
 	resp, err := c.config.Client.Do(req)
 	if err != nil {
@@ -77,37 +125,42 @@ func (c *Client) do(
 		_ = resp.Body.Close()
 	}()
 
+	result := &Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+	}
+
 	if resp.StatusCode >= http.StatusBadRequest {
-		body, _ := io.ReadAll(resp.Body)
+		respBody, _ := io.ReadAll(resp.Body)
 
-		return resp.Header, c.formatError(resp.StatusCode, body)
+		return result, c.formatError(r.Method, r.Path, resp.StatusCode, resp.Header, respBody)
 	}
 
 	if resp.StatusCode == http.StatusNoContent {
-		return resp.Header, nil
+		return result, nil
 	}
 
-	if response != nil {
-		if decErr := json.NewDecoder(resp.Body).Decode(response); decErr != nil {
-			return nil, fmt.Errorf("failed to decode response: %w", decErr)
+	if r.Response != nil {
+		if decErr := json.NewDecoder(resp.Body).Decode(r.Response); decErr != nil {
+			return result, fmt.Errorf("failed to decode response: %w", decErr)
 		}
 	}
 
-	return resp.Header, nil
+	return result, nil
 }
 
-func (c *Client) formatError(statusCode int, body []byte) error {
-	switch statusCode {
-	case http.StatusBadRequest:
-		return fmt.Errorf("%w: %s", ErrBadRequest, string(body))
-	case http.StatusConflict:
-		return fmt.Errorf("%w: %s", ErrConflict, string(body))
+func (c *Client) formatError(method, path string, statusCode int, header http.Header, body []byte) error {
+	apiErr := newAPIError(method, path, statusCode, header, body)
+	if statusCode == http.StatusTooManyRequests {
+		return newTooManyRequestsError(apiErr, time.Now())
 	}
 
-	if statusCode >= http.StatusInternalServerError {
-		return fmt.Errorf("%w: unexpected status code %d with body %s", ErrServer, statusCode, string(body))
-	}
+	return apiErr
+}
 
-	// All other client errors (400-499)
-	return fmt.Errorf("%w: unexpected status code %d with body %s", ErrClient, statusCode, string(body))
+func cloneHeaders(headers map[string]string) map[string]string {
+	if headers == nil {
+		return make(map[string]string)
+	}
+	return maps.Clone(headers)
 }
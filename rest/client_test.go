@@ -7,6 +7,7 @@ import (
 	"math"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/android-sms-gateway/client-go/rest"
@@ -26,6 +27,12 @@ func setupTestServer(t *testing.T) *httptest.Server {
 			w.WriteHeader(http.StatusBadRequest)
 			_, _ = w.Write([]byte("bad request"))
 			return
+		case "/401":
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		case "/403":
+			w.WriteHeader(http.StatusForbidden)
+			return
 		case "/404":
 			w.WriteHeader(http.StatusNotFound)
 			_, _ = w.Write([]byte("not found"))
@@ -34,6 +41,12 @@ func setupTestServer(t *testing.T) *httptest.Server {
 			w.WriteHeader(http.StatusConflict)
 			_, _ = w.Write([]byte("conflict"))
 			return
+		case "/413":
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		case "/418":
+			w.WriteHeader(http.StatusTeapot)
+			return
 		case "/500":
 			w.WriteHeader(http.StatusInternalServerError)
 			_, _ = w.Write([]byte("internal server error"))
@@ -143,6 +156,66 @@ func TestClient_Do(t *testing.T) {
 				path:   "/404",
 			},
 			wantErr:     true,
+			wantErrType: rest.ErrNotFound,
+		},
+		{
+			name: "HTTP 401 error",
+			fields: fields{
+				config: rest.Config{
+					BaseURL: httpServer.URL,
+				},
+			},
+			args: args{
+				ctx:    context.Background(),
+				method: http.MethodGet,
+				path:   "/401",
+			},
+			wantErr:     true,
+			wantErrType: rest.ErrUnauthorized,
+		},
+		{
+			name: "HTTP 403 error",
+			fields: fields{
+				config: rest.Config{
+					BaseURL: httpServer.URL,
+				},
+			},
+			args: args{
+				ctx:    context.Background(),
+				method: http.MethodGet,
+				path:   "/403",
+			},
+			wantErr:     true,
+			wantErrType: rest.ErrForbidden,
+		},
+		{
+			name: "HTTP 413 error",
+			fields: fields{
+				config: rest.Config{
+					BaseURL: httpServer.URL,
+				},
+			},
+			args: args{
+				ctx:    context.Background(),
+				method: http.MethodGet,
+				path:   "/413",
+			},
+			wantErr:     true,
+			wantErrType: rest.ErrPayloadTooLarge,
+		},
+		{
+			name: "HTTP 418 error",
+			fields: fields{
+				config: rest.Config{
+					BaseURL: httpServer.URL,
+				},
+			},
+			args: args{
+				ctx:    context.Background(),
+				method: http.MethodGet,
+				path:   "/418",
+			},
+			wantErr:     true,
 			wantErrType: rest.ErrClient,
 		},
 		{
@@ -245,3 +318,74 @@ func TestClient_Do(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_Do_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "abc")
+		switch r.URL.Path {
+		case "/json":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"invalid phone number","code":42,"data":{"field":"phoneNumbers"}}`))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("upstream unavailable"))
+		}
+	}))
+	defer server.Close()
+
+	c := rest.NewClient(rest.Config{BaseURL: server.URL})
+
+	t.Run("JSON body", func(t *testing.T) {
+		err := c.Do(context.Background(), http.MethodPost, "/json", nil, nil, nil)
+		if !errors.Is(err, rest.ErrBadRequest) {
+			t.Fatalf("expected ErrBadRequest, got %v", err)
+		}
+
+		apiErr, ok := rest.AsAPIError(err)
+		if !ok {
+			t.Fatalf("expected *APIError, got %T", err)
+		}
+		if apiErr.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected status code 400, got %d", apiErr.StatusCode)
+		}
+		if apiErr.Method != http.MethodPost || apiErr.Path != "/json" {
+			t.Errorf("unexpected request %s %s", apiErr.Method, apiErr.Path)
+		}
+		if apiErr.Header.Get("X-Request-Id") != "abc" {
+			t.Errorf("expected response headers, got %v", apiErr.Header)
+		}
+		if apiErr.Response == nil {
+			t.Fatal("expected decoded error document, got nil")
+		}
+		if apiErr.Code() != 42 {
+			t.Errorf("expected code 42, got %d", apiErr.Code())
+		}
+		if apiErr.Message() != "invalid phone number" {
+			t.Errorf("expected message %q, got %q", "invalid phone number", apiErr.Message())
+		}
+		if !reflect.DeepEqual(apiErr.Response.Data, map[string]any{"field": "phoneNumbers"}) {
+			t.Errorf("unexpected data %v", apiErr.Response.Data)
+		}
+	})
+
+	t.Run("Plain text body", func(t *testing.T) {
+		err := c.Do(context.Background(), http.MethodGet, "/plain", nil, nil, nil)
+		if !errors.Is(err, rest.ErrServer) {
+			t.Fatalf("expected ErrServer, got %v", err)
+		}
+
+		var apiErr *rest.APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected *APIError, got %T", err)
+		}
+		if apiErr.Response != nil {
+			t.Errorf("expected no decoded document, got %v", apiErr.Response)
+		}
+		if apiErr.Message() != "upstream unavailable" {
+			t.Errorf("expected raw body as message, got %q", apiErr.Message())
+		}
+		if apiErr.Code() != 0 {
+			t.Errorf("expected zero code, got %d", apiErr.Code())
+		}
+	})
+}
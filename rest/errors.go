@@ -1,8 +1,10 @@
 package rest
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 )
 
 var (
@@ -12,10 +14,119 @@ var (
 )
 
 var (
-	ErrBadRequest = fmt.Errorf("%w: validation failed", ErrClient)
-	ErrConflict   = fmt.Errorf("%w: conflict", ErrClient)
+	ErrBadRequest      = fmt.Errorf("%w: validation failed", ErrClient)
+	ErrUnauthorized    = fmt.Errorf("%w: unauthorized", ErrClient)
+	ErrForbidden       = fmt.Errorf("%w: forbidden", ErrClient)
+	ErrNotFound        = fmt.Errorf("%w: not found", ErrClient)
+	ErrConflict        = fmt.Errorf("%w: conflict", ErrClient)
+	ErrPayloadTooLarge = fmt.Errorf("%w: payload too large", ErrClient)
+	ErrTooManyRequests = fmt.Errorf("%w: too many requests", ErrClient)
 )
 
+// APIError is returned when the server responds with a 4xx or 5xx status code.
+//
+// StatusCode, Method and Path identify the failed request.
+//
+// Header and Body are the response headers and the raw response body.
+//
+// Response is the decoded error document, nil if the body is not a JSON object.
+//
+// APIError wraps one of the sentinel errors above, so it can be matched with
+// both errors.As and errors.Is (e.g. `errors.Is(err, ErrBadRequest)`).
+type APIError struct {
+	StatusCode int            // Response status code
+	Method     string         // Request method
+	Path       string         // Request path, relative to the base URL
+	Header     http.Header    // Response headers
+	Body       []byte         // Raw response body
+	Response   *ErrorResponse // Decoded error document, nil if the body is not a JSON object
+
+	err error
+}
+
+func newAPIError(method, path string, statusCode int, header http.Header, body []byte) *APIError {
+	var response *ErrorResponse
+	if decoded := new(ErrorResponse); json.Unmarshal(body, decoded) == nil {
+		response = decoded
+	}
+
+	return &APIError{
+		StatusCode: statusCode,
+		Method:     method,
+		Path:       path,
+		Header:     header,
+		Body:       body,
+		Response:   response,
+
+		err: formatStatusError(statusCode, body),
+	}
+}
+
+func (e *APIError) Error() string {
+	if e.err == nil {
+		return ErrAPIError.Error()
+	}
+	return e.err.Error()
+}
+
+func (e *APIError) Unwrap() error {
+	if e.err == nil {
+		return ErrAPIError
+	}
+	return e.err
+}
+
+// Message returns the error message from the decoded error document, or the
+// raw body if the document is not available.
+func (e *APIError) Message() string {
+	if e.Response != nil && e.Response.Message != "" {
+		return e.Response.Message
+	}
+	return string(e.Body)
+}
+
+// Code returns the error code from the decoded error document, or zero if it
+// is not available.
+func (e *APIError) Code() int32 {
+	if e.Response == nil {
+		return 0
+	}
+	return e.Response.Code
+}
+
+// AsAPIError returns the APIError from the error chain, if any.
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	ok := errors.As(err, &apiErr)
+	return apiErr, ok
+}
+
+func formatStatusError(statusCode int, body []byte) error {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return fmt.Errorf("%w: %s", ErrBadRequest, string(body))
+	case http.StatusUnauthorized:
+		return fmt.Errorf("%w: %s", ErrUnauthorized, string(body))
+	case http.StatusForbidden:
+		return fmt.Errorf("%w: %s", ErrForbidden, string(body))
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, string(body))
+	case http.StatusRequestEntityTooLarge:
+		return fmt.Errorf("%w: %s", ErrPayloadTooLarge, string(body))
+	case http.StatusConflict:
+		return fmt.Errorf("%w: %s", ErrConflict, string(body))
+	case http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %s", ErrTooManyRequests, string(body))
+	}
+
+	if statusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: unexpected status code %d with body %s", ErrServer, statusCode, string(body))
+	}
+
+	// All other client errors (400-499)
+	return fmt.Errorf("%w: unexpected status code %d with body %s", ErrClient, statusCode, string(body))
+}
+
 func IsAPIError(err error) bool {
 	return errors.Is(err, ErrAPIError)
 }
@@ -35,3 +146,23 @@ func IsConflict(err error) bool {
 func IsBadRequest(err error) bool {
 	return errors.Is(err, ErrBadRequest)
 }
+
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+func IsForbidden(err error) bool {
+	return errors.Is(err, ErrForbidden)
+}
+
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+func IsPayloadTooLarge(err error) bool {
+	return errors.Is(err, ErrPayloadTooLarge)
+}
+
+func IsTooManyRequests(err error) bool {
+	return errors.Is(err, ErrTooManyRequests)
+}
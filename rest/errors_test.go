@@ -2,6 +2,7 @@ package rest_test
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	liberr "github.com/android-sms-gateway/client-go/rest"
@@ -22,6 +23,11 @@ func TestIsAPIError(t *testing.T) {
 		{"Server error", liberr.ErrServer, true},
 		{"Bad request", liberr.ErrBadRequest, true},
 		{"Conflict", liberr.ErrConflict, true},
+		{"Unauthorized", liberr.ErrUnauthorized, true},
+		{"Forbidden", liberr.ErrForbidden, true},
+		{"Not found", liberr.ErrNotFound, true},
+		{"Payload too large", liberr.ErrPayloadTooLarge, true},
+		{"Too many requests", liberr.ErrTooManyRequests, true},
 		{"Non-API error", errSomeOther, false},
 	}
 
@@ -40,6 +46,11 @@ func TestIsClientError(t *testing.T) {
 		{"Client error", liberr.ErrClient, true},
 		{"Bad request", liberr.ErrBadRequest, true},
 		{"Conflict", liberr.ErrConflict, true},
+		{"Unauthorized", liberr.ErrUnauthorized, true},
+		{"Forbidden", liberr.ErrForbidden, true},
+		{"Not found", liberr.ErrNotFound, true},
+		{"Payload too large", liberr.ErrPayloadTooLarge, true},
+		{"Too many requests", liberr.ErrTooManyRequests, true},
 		{"API error", liberr.ErrAPIError, false},
 		{"Server error", liberr.ErrServer, false},
 		{"Non-client error", errSomeOther, false},
@@ -114,3 +125,104 @@ func TestIsBadRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestIsTooManyRequests(t *testing.T) {
+	tests := []testCase{
+		{"Too many requests", liberr.ErrTooManyRequests, true},
+		{"Typed error", &liberr.TooManyRequestsError{}, true},
+		{"Client error", liberr.ErrClient, false},
+		{"API error", liberr.ErrAPIError, false},
+		{"Server error", liberr.ErrServer, false},
+		{"Conflict", liberr.ErrConflict, false},
+		{"Non-too-many-requests error", errSomeOther, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := liberr.IsTooManyRequests(tc.err)
+			if result != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestIsUnauthorized(t *testing.T) {
+	tests := []testCase{
+		{"Unauthorized", liberr.ErrUnauthorized, true},
+		{"Wrapped", fmt.Errorf("wrapped: %w", liberr.ErrUnauthorized), true},
+		{"Client error", liberr.ErrClient, false},
+		{"API error", liberr.ErrAPIError, false},
+		{"Bad request", liberr.ErrBadRequest, false},
+		{"Non-unauthorized error", errSomeOther, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := liberr.IsUnauthorized(tc.err)
+			if result != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestIsForbidden(t *testing.T) {
+	tests := []testCase{
+		{"Forbidden", liberr.ErrForbidden, true},
+		{"Wrapped", fmt.Errorf("wrapped: %w", liberr.ErrForbidden), true},
+		{"Client error", liberr.ErrClient, false},
+		{"API error", liberr.ErrAPIError, false},
+		{"Bad request", liberr.ErrBadRequest, false},
+		{"Non-forbidden error", errSomeOther, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := liberr.IsForbidden(tc.err)
+			if result != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	tests := []testCase{
+		{"Not found", liberr.ErrNotFound, true},
+		{"Wrapped", fmt.Errorf("wrapped: %w", liberr.ErrNotFound), true},
+		{"Client error", liberr.ErrClient, false},
+		{"API error", liberr.ErrAPIError, false},
+		{"Bad request", liberr.ErrBadRequest, false},
+		{"Non-not-found error", errSomeOther, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := liberr.IsNotFound(tc.err)
+			if result != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestIsPayloadTooLarge(t *testing.T) {
+	tests := []testCase{
+		{"Payload too large", liberr.ErrPayloadTooLarge, true},
+		{"Wrapped", fmt.Errorf("wrapped: %w", liberr.ErrPayloadTooLarge), true},
+		{"Client error", liberr.ErrClient, false},
+		{"API error", liberr.ErrAPIError, false},
+		{"Bad request", liberr.ErrBadRequest, false},
+		{"Non-payload-too-large error", errSomeOther, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := liberr.IsPayloadTooLarge(tc.err)
+			if result != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
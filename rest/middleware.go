@@ -0,0 +1,63 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+)
+
+// Request describes a single API call as seen by middlewares.
+//
+// Method and Path identify the endpoint, Path is relative to the base URL.
+//
+// Headers are the request headers; middlewares may modify them, e.g. to add
+// authentication or tracing headers.
+//
+// Payload is the request body before JSON encoding, nil if the request has no
+// body.
+//
+// Response is the value the response body is decoded into, nil if the body is
+// discarded.
+type Request struct {
+	Method   string            // HTTP method
+	Path     string            // Path relative to the base URL, including the query string
+	Headers  map[string]string // Request headers
+	Payload  any               // Request body before JSON encoding
+	Response any               // Target for the decoded response body
+}
+
+// Response describes the outcome of a single API call.
+//
+// It is nil if the request failed before a response was received.
+type Response struct {
+	StatusCode int         // Response status code
+	Header     http.Header // Response headers
+}
+
+// RoundTripFunc performs an API call.
+//
+// A non-nil Response is returned together with an error when the server
+// responded with an error status code.
+type RoundTripFunc func(ctx context.Context, req *Request) (*Response, error)
+
+// Middleware wraps a RoundTripFunc to add behavior around every API call, such
+// as custom headers, request signing, logging, metrics or fault injection.
+//
+// Middlewares run for every attempt, so they observe retries individually.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// RequestEditorFunc modifies an HTTP request right before it is sent, after
+// the headers from Request have been applied. Unlike a Middleware, it has
+// access to the raw request, e.g. to authenticate or sign it.
+type RequestEditorFunc func(ctx context.Context, req *http.Request) error
+
+// chain composes middlewares so that the first one is the outermost.
+func chain(middlewares []Middleware) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			if middlewares[i] != nil {
+				next = middlewares[i](next)
+			}
+		}
+		return next
+	}
+}
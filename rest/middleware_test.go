@@ -0,0 +1,184 @@
+package rest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/android-sms-gateway/client-go/rest"
+)
+
+var errInjected = errors.New("injected fault")
+
+func TestClient_Do_Middlewares(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Custom") != "value" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("X-Server", "test")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id":"123"}`))
+	}))
+	defer server.Close()
+
+	var order []string
+	trace := func(name string) rest.Middleware {
+		return func(next rest.RoundTripFunc) rest.RoundTripFunc {
+			return func(ctx context.Context, req *rest.Request) (*rest.Response, error) {
+				order = append(order, name+":before")
+				resp, err := next(ctx, req)
+				order = append(order, name+":after")
+				return resp, err
+			}
+		}
+	}
+
+	var (
+		seen     rest.Request
+		seenResp *rest.Response
+	)
+	inspect := func(next rest.RoundTripFunc) rest.RoundTripFunc {
+		return func(ctx context.Context, req *rest.Request) (*rest.Response, error) {
+			req.Headers["X-Custom"] = "value"
+			seen = *req
+			resp, err := next(ctx, req)
+			seenResp = resp
+			return resp, err
+		}
+	}
+
+	c := rest.NewClient(rest.Config{
+		BaseURL:     server.URL,
+		Middlewares: []rest.Middleware{trace("outer"), trace("inner"), inspect},
+	})
+
+	headers := map[string]string{"X-Caller": "1"}
+	payload := map[string]string{"foo": "bar"}
+	response := new(struct {
+		ID string `json:"id"`
+	})
+
+	if err := c.Do(context.Background(), http.MethodPost, "/path?q=1", headers, payload, response); err != nil {
+		t.Fatalf("Client.Do() unexpected error = %v", err)
+	}
+
+	wantOrder := []string{"outer:before", "inner:before", "inner:after", "outer:after"}
+	if !reflect.DeepEqual(order, wantOrder) {
+		t.Errorf("expected order %v, got %v", wantOrder, order)
+	}
+
+	if seen.Method != http.MethodPost || seen.Path != "/path?q=1" {
+		t.Errorf("unexpected request %s %s", seen.Method, seen.Path)
+	}
+	if !reflect.DeepEqual(seen.Payload, payload) {
+		t.Errorf("expected payload %v, got %v", payload, seen.Payload)
+	}
+	if seen.Response != response {
+		t.Errorf("expected response target to be passed through")
+	}
+	if _, ok := headers["X-Custom"]; ok {
+		t.Errorf("middleware must not modify caller headers")
+	}
+
+	if response.ID != "123" {
+		t.Errorf("expected decoded ID 123, got %q", response.ID)
+	}
+	if seenResp == nil || seenResp.StatusCode != http.StatusOK || seenResp.Header.Get("X-Server") != "test" {
+		t.Errorf("unexpected response %+v", seenResp)
+	}
+}
+
+func TestClient_Do_MiddlewareFaultInjection(t *testing.T) {
+	server, calls := newFlakyServer(0, http.StatusOK)
+	defer server.Close()
+
+	attempts := new(atomic.Int32)
+	faulty := func(next rest.RoundTripFunc) rest.RoundTripFunc {
+		return func(ctx context.Context, req *rest.Request) (*rest.Response, error) {
+			if attempts.Add(1) == 1 {
+				return nil, errInjected
+			}
+			return next(ctx, req)
+		}
+	}
+
+	policy := fastRetryPolicy(3)
+	policy.RetryError = func(err error) bool {
+		return errors.Is(err, errInjected)
+	}
+
+	c := rest.NewClient(rest.Config{
+		BaseURL:     server.URL,
+		RetryPolicy: policy,
+		Middlewares: []rest.Middleware{faulty},
+	})
+
+	if err := c.Do(context.Background(), http.MethodGet, "/", nil, nil, nil); err != nil {
+		t.Fatalf("Client.Do() unexpected error = %v", err)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("expected middleware to see 2 attempts, got %d", got)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected 1 server call, got %d", got)
+	}
+}
+
+func TestClient_Do_MiddlewareShortCircuit(t *testing.T) {
+	c := rest.NewClient(rest.Config{
+		BaseURL: "http://invalid.invalid",
+		Middlewares: []rest.Middleware{
+			func(_ rest.RoundTripFunc) rest.RoundTripFunc {
+				return func(_ context.Context, _ *rest.Request) (*rest.Response, error) {
+					return nil, errInjected
+				}
+			},
+		},
+	})
+
+	if err := c.Do(context.Background(), http.MethodGet, "/", nil, nil, nil); !errors.Is(err, errInjected) {
+		t.Errorf("expected injected error, got %v", err)
+	}
+}
+
+func TestClient_Do_RequestEditors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Signature") != "POST /signed application/json" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sign := func(_ context.Context, req *http.Request) error {
+		req.Header.Set("X-Signature", req.Method+" "+req.URL.Path+" "+req.Header.Get("Content-Type"))
+		return nil
+	}
+
+	c := rest.NewClient(rest.Config{
+		BaseURL:        server.URL,
+		RequestEditors: []rest.RequestEditorFunc{sign},
+	})
+
+	if err := c.Do(context.Background(), http.MethodPost, "/signed", nil, map[string]string{}, nil); err != nil {
+		t.Errorf("Client.Do() unexpected error = %v", err)
+	}
+
+	failing := rest.NewClient(rest.Config{
+		BaseURL: server.URL,
+		RequestEditors: []rest.RequestEditorFunc{
+			func(context.Context, *http.Request) error { return errInjected },
+		},
+	})
+
+	if err := failing.Do(context.Background(), http.MethodGet, "/", nil, nil, nil); !errors.Is(err, errInjected) {
+		t.Errorf("expected injected error, got %v", err)
+	}
+}
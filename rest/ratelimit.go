@@ -0,0 +1,113 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	headerRetryAfter         = "Retry-After"
+	headerRateLimitLimit     = "X-Ratelimit-Limit"
+	headerRateLimitRemaining = "X-Ratelimit-Remaining"
+	headerRateLimitReset     = "X-Ratelimit-Reset"
+
+	headerIETFRateLimitLimit     = "Ratelimit-Limit"
+	headerIETFRateLimitRemaining = "Ratelimit-Remaining"
+	headerIETFRateLimitReset     = "Ratelimit-Reset"
+
+	// Reset values above this threshold are treated as Unix timestamps,
+	// smaller ones as a number of seconds from now.
+	unixTimestampThreshold = 1_000_000_000
+)
+
+// TooManyRequestsError is returned when the server responds with
+// `429 Too Many Requests`.
+//
+// RetryAfter is the parsed `Retry-After` header, zero if absent.
+//
+// Limit and Remaining are the parsed `X-RateLimit-Limit` and
+// `X-RateLimit-Remaining` headers, -1 if absent.
+//
+// Reset is the parsed `X-RateLimit-Reset` header, zero if absent.
+//
+// The embedded APIError holds the response details, such as headers and body.
+type TooManyRequestsError struct {
+	*APIError
+
+	RetryAfter time.Duration // Time to wait before retrying
+	Limit      int           // Request limit for the current window, -1 if unknown
+	Remaining  int           // Requests remaining in the current window, -1 if unknown
+	Reset      time.Time     // Time at which the current window resets
+}
+
+func newTooManyRequestsError(apiErr *APIError, now time.Time) *TooManyRequestsError {
+	return &TooManyRequestsError{
+		APIError: apiErr,
+
+		RetryAfter: parseRetryAfter(apiErr.Header.Get(headerRetryAfter), now),
+		Limit:      parseIntHeader(apiErr.Header, headerRateLimitLimit, headerIETFRateLimitLimit),
+		Remaining:  parseIntHeader(apiErr.Header, headerRateLimitRemaining, headerIETFRateLimitRemaining),
+		Reset:      parseReset(apiErr.Header, now),
+	}
+}
+
+func (e *TooManyRequestsError) Error() string {
+	msg := ErrTooManyRequests.Error()
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(", retry after %s", e.RetryAfter)
+	}
+	if e.APIError != nil && len(e.Body) > 0 {
+		msg += ": " + string(e.Body)
+	}
+	return msg
+}
+
+func (e *TooManyRequestsError) Unwrap() error {
+	if e.APIError == nil {
+		return ErrTooManyRequests
+	}
+	return e.APIError
+}
+
+// parseRetryAfter parses the value of a `Retry-After` header, which is either
+// a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+
+	return 0
+}
+
+func parseIntHeader(header http.Header, keys ...string) int {
+	for _, key := range keys {
+		if v, err := strconv.Atoi(strings.TrimSpace(header.Get(key))); err == nil {
+			return v
+		}
+	}
+	return -1
+}
+
+func parseReset(header http.Header, now time.Time) time.Time {
+	v := parseIntHeader(header, headerRateLimitReset, headerIETFRateLimitReset)
+	switch {
+	case v < 0:
+		return time.Time{}
+	case v > unixTimestampThreshold:
+		return time.Unix(int64(v), 0)
+	default:
+		return now.Add(time.Duration(v) * time.Second)
+	}
+}
@@ -0,0 +1,190 @@
+package rest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/rest"
+)
+
+func newRateLimitedServer(failures int32, headers map[string]string) (*httptest.Server, *atomic.Int32) {
+	calls := new(atomic.Int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) <= failures {
+			for k, v := range headers {
+				w.Header().Set(k, v)
+			}
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte("slow down"))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	return server, calls
+}
+
+func TestClient_Do_TooManyRequests(t *testing.T) {
+	server, _ := newRateLimitedServer(1, map[string]string{
+		"Retry-After":           "7",
+		"X-RateLimit-Limit":     "100",
+		"X-RateLimit-Remaining": "0",
+		"X-RateLimit-Reset":     "30",
+	})
+	defer server.Close()
+
+	c := rest.NewClient(rest.Config{BaseURL: server.URL})
+
+	before := time.Now()
+	err := c.Do(context.Background(), http.MethodPost, "/", nil, nil, nil)
+	if !errors.Is(err, rest.ErrTooManyRequests) {
+		t.Fatalf("expected ErrTooManyRequests, got %v", err)
+	}
+	if !rest.IsClientError(err) {
+		t.Errorf("expected client error, got %v", err)
+	}
+
+	var tooManyErr *rest.TooManyRequestsError
+	if !errors.As(err, &tooManyErr) {
+		t.Fatalf("expected *TooManyRequestsError, got %T", err)
+	}
+	if tooManyErr.RetryAfter != 7*time.Second {
+		t.Errorf("expected RetryAfter 7s, got %s", tooManyErr.RetryAfter)
+	}
+	if tooManyErr.Limit != 100 {
+		t.Errorf("expected Limit 100, got %d", tooManyErr.Limit)
+	}
+	if tooManyErr.Remaining != 0 {
+		t.Errorf("expected Remaining 0, got %d", tooManyErr.Remaining)
+	}
+	if tooManyErr.Reset.Before(before.Add(29*time.Second)) || tooManyErr.Reset.After(time.Now().Add(30*time.Second)) {
+		t.Errorf("unexpected Reset %s", tooManyErr.Reset)
+	}
+	if string(tooManyErr.Body) != "slow down" {
+		t.Errorf("expected body %q, got %q", "slow down", tooManyErr.Body)
+	}
+}
+
+func TestClient_Do_TooManyRequestsWithoutHeaders(t *testing.T) {
+	server, _ := newRateLimitedServer(1, nil)
+	defer server.Close()
+
+	c := rest.NewClient(rest.Config{BaseURL: server.URL})
+
+	var tooManyErr *rest.TooManyRequestsError
+	err := c.Do(context.Background(), http.MethodGet, "/", nil, nil, nil)
+	if !errors.As(err, &tooManyErr) {
+		t.Fatalf("expected *TooManyRequestsError, got %v", err)
+	}
+	if tooManyErr.RetryAfter != 0 || tooManyErr.Limit != -1 || tooManyErr.Remaining != -1 {
+		t.Errorf("unexpected parsed values %+v", tooManyErr)
+	}
+	if !tooManyErr.Reset.IsZero() {
+		t.Errorf("expected zero Reset, got %s", tooManyErr.Reset)
+	}
+}
+
+func TestClient_Do_RetryTooManyRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		optIn      bool
+		maxWait    time.Duration
+		wantErr    error
+		wantCalls  int32
+	}{
+		{
+			name:       "Disabled by default",
+			retryAfter: "0",
+			optIn:      false,
+			wantErr:    rest.ErrTooManyRequests,
+			wantCalls:  1,
+		},
+		{
+			name:       "Retries non-idempotent requests",
+			retryAfter: "0",
+			optIn:      true,
+			wantErr:    nil,
+			wantCalls:  3,
+		},
+		{
+			name:       "Retry-After above limit",
+			retryAfter: "3600",
+			optIn:      true,
+			maxWait:    time.Minute,
+			wantErr:    rest.ErrTooManyRequests,
+			wantCalls:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := newRateLimitedServer(2, map[string]string{"Retry-After": tt.retryAfter})
+			defer server.Close()
+
+			policy := fastRetryPolicy(3)
+			policy.RetryStatusCodes = []int{}
+			policy.RetryTooManyRequests = tt.optIn
+			policy.MaxRetryAfter = tt.maxWait
+
+			c := rest.NewClient(rest.Config{
+				BaseURL:     server.URL,
+				RetryPolicy: policy,
+			})
+
+			err := c.Do(context.Background(), http.MethodPost, "/", nil, nil, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Client.Do() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("expected %d calls, got %d", tt.wantCalls, got)
+			}
+		})
+	}
+}
+
+func TestClient_Do_RetryAfterDate(t *testing.T) {
+	server, calls := newRateLimitedServer(1, map[string]string{
+		"Retry-After": time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat),
+	})
+	defer server.Close()
+
+	policy := fastRetryPolicy(2)
+	policy.RetryTooManyRequests = true
+
+	c := rest.NewClient(rest.Config{
+		BaseURL:     server.URL,
+		RetryPolicy: policy,
+	})
+
+	if err := c.Do(context.Background(), http.MethodGet, "/", nil, nil, nil); err != nil {
+		t.Errorf("Client.Do() unexpected error = %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected 2 calls, got %d", got)
+	}
+}
+
+func TestTooManyRequestsError_APIError(t *testing.T) {
+	server, _ := newRateLimitedServer(1, nil)
+	defer server.Close()
+
+	c := rest.NewClient(rest.Config{BaseURL: server.URL})
+
+	err := c.Do(context.Background(), http.MethodGet, "/limited", nil, nil, nil)
+
+	apiErr, ok := rest.AsAPIError(err)
+	if !ok {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+	if apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Path != "/limited" {
+		t.Errorf("unexpected APIError %+v", apiErr)
+	}
+}
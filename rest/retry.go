@@ -0,0 +1,227 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+const (
+	DefaultMaxAttempts = 3
+	DefaultBaseDelay   = 500 * time.Millisecond
+	DefaultMaxDelay    = 10 * time.Second
+	DefaultJitter      = 0.2
+)
+
+// idempotentKey is the context key marking requests as safe to retry.
+type idempotentKey struct{}
+
+// WithIdempotent returns a context that marks requests made with it as safe to
+// retry regardless of their method, e.g. a POST that the server deduplicates
+// by a client-generated ID.
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// isIdempotent reports whether the request with the method and context is safe
+// to retry.
+func isIdempotent(ctx context.Context, method string) bool {
+	marked, _ := ctx.Value(idempotentKey{}).(bool)
+	return marked || IsIdempotentMethod(method)
+}
+
+// DefaultRetryStatusCodes returns the status codes retried when
+// RetryPolicy.RetryStatusCodes is nil.
+func DefaultRetryStatusCodes() []int {
+	return []int{
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
+}
+
+// RetryPolicy controls how failed requests are retried.
+//
+// MaxAttempts is the total number of attempts, including the first one. Values
+// less than 2 disable retries.
+//
+// BaseDelay is the delay before the first retry. Each subsequent retry doubles
+// it, up to MaxDelay. Zero values fall back to DefaultBaseDelay and
+// DefaultMaxDelay.
+//
+// Jitter randomizes each delay by up to the given fraction (0..1) to avoid
+// synchronized retries from many clients.
+//
+// RetryStatusCodes lists the response status codes to retry. If nil,
+// DefaultRetryStatusCodes is used; an empty non-nil slice disables retries on
+// status codes.
+//
+// RetryError decides whether a request that failed without a response should
+// be retried. If nil, transport errors are retried unless the context is done.
+//
+// RetryNonIdempotent allows retrying methods that are not idempotent, such as
+// POST and PATCH. By default only idempotent methods and requests with a
+// context from WithIdempotent are retried.
+//
+// RetryTooManyRequests enables waiting and retrying on `429 Too Many Requests`
+// responses for any method, as the server did not process the request. The
+// delay is taken from the `Retry-After` header, falling back to the backoff
+// delay. If the server asks to wait longer than MaxRetryAfter (when set), the
+// TooManyRequestsError is returned immediately.
+type RetryPolicy struct {
+	MaxAttempts        int                  // Total number of attempts, including the first one
+	BaseDelay          time.Duration        // Delay before the first retry, defaults to `DefaultBaseDelay`
+	MaxDelay           time.Duration        // Upper bound for a single delay, defaults to `DefaultMaxDelay`
+	Jitter             float64              // Fraction (0..1) of the delay to randomize
+	RetryStatusCodes   []int                // Status codes to retry, defaults to `DefaultRetryStatusCodes()`
+	RetryError         func(err error) bool // Optional classifier for errors without a response
+	RetryNonIdempotent bool                 // Allow retrying non-idempotent methods
+
+	RetryTooManyRequests bool          // Wait and retry on `429 Too Many Requests`
+	MaxRetryAfter        time.Duration // Upper bound for honoring `Retry-After`, unlimited if zero
+}
+
+// DefaultRetryPolicy returns a policy with sensible defaults: up to three
+// attempts of idempotent requests with exponential backoff.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:        DefaultMaxAttempts,
+		BaseDelay:          DefaultBaseDelay,
+		MaxDelay:           DefaultMaxDelay,
+		Jitter:             DefaultJitter,
+		RetryStatusCodes:   nil,
+		RetryError:         nil,
+		RetryNonIdempotent: false,
+
+		RetryTooManyRequests: false,
+		MaxRetryAfter:        0,
+	}
+}
+
+// IsIdempotentMethod reports whether the HTTP method is idempotent as defined
+// by RFC 9110.
+func IsIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// shouldRetry reports whether an attempt of a request that is idempotent or
+// not, which finished with the given status code (zero when there is no
+// response) and error, should be retried.
+func (p *RetryPolicy) shouldRetry(idempotent bool, attempt, statusCode int, err error) bool {
+	if p == nil || err == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	if statusCode == http.StatusTooManyRequests && p.RetryTooManyRequests {
+		var tooManyErr *TooManyRequestsError
+		if errors.As(err, &tooManyErr) && p.MaxRetryAfter > 0 && tooManyErr.RetryAfter > p.MaxRetryAfter {
+			return false
+		}
+		return true
+	}
+
+	if !p.RetryNonIdempotent && !idempotent {
+		return false
+	}
+
+	if statusCode != 0 {
+		codes := p.RetryStatusCodes
+		if codes == nil {
+			codes = DefaultRetryStatusCodes()
+		}
+		return slices.Contains(codes, statusCode)
+	}
+
+	if p.RetryError != nil {
+		return p.RetryError(err)
+	}
+
+	return IsTransportError(err)
+}
+
+// delay returns the delay before the given retry (1-based) after err,
+// honoring the server-provided `Retry-After` value when present.
+func (p *RetryPolicy) delay(retry int, err error) time.Duration {
+	var tooManyErr *TooManyRequestsError
+	if errors.As(err, &tooManyErr) && tooManyErr.RetryAfter > 0 {
+		return tooManyErr.RetryAfter
+	}
+
+	return p.backoff(retry)
+}
+
+// backoff returns the delay before the given retry (1-based).
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		base = DefaultBaseDelay
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxDelay
+	}
+
+	delay := base
+	for i := 1; i < retry && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxDelay)
+
+	if p.Jitter > 0 {
+		jitter := min(p.Jitter, 1)
+		//nolint:gosec // jitter does not need a cryptographically secure source
+		delay -= time.Duration(float64(delay) * jitter * rand.Float64())
+	}
+
+	return delay
+}
+
+// IsTransient reports whether err is a `429 Too Many Requests` response, a
+// response with one of DefaultRetryStatusCodes or a transport error, so the
+// same request may succeed later. Context errors are not transient.
+func IsTransient(err error) bool {
+	if apiErr, ok := AsAPIError(err); ok {
+		return apiErr.StatusCode == http.StatusTooManyRequests ||
+			slices.Contains(DefaultRetryStatusCodes(), apiErr.StatusCode)
+	}
+
+	return IsTransportError(err)
+}
+
+// IsTransportError reports whether err was caused by the HTTP transport rather
+// than by the caller, e.g. a refused connection or a reset stream. Context
+// errors are not transport errors.
+func IsTransportError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// sleep waits for the given duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
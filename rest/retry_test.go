@@ -0,0 +1,255 @@
+package rest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/rest"
+)
+
+func newFlakyServer(failures int32, failStatus int) (*httptest.Server, *atomic.Int32) {
+	calls := new(atomic.Int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(failStatus)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	return server, calls
+}
+
+func fastRetryPolicy(attempts int) *rest.RetryPolicy {
+	policy := rest.DefaultRetryPolicy()
+	policy.MaxAttempts = attempts
+	policy.BaseDelay = time.Millisecond
+	policy.MaxDelay = 5 * time.Millisecond
+	return policy
+}
+
+func TestClient_Do_Retry(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     *rest.RetryPolicy
+		method     string
+		ctx        context.Context
+		failures   int32
+		failStatus int
+		wantErr    error
+		wantCalls  int32
+	}{
+		{
+			name:       "No policy",
+			policy:     nil,
+			method:     http.MethodGet,
+			failures:   1,
+			failStatus: http.StatusServiceUnavailable,
+			wantErr:    rest.ErrServer,
+			wantCalls:  1,
+		},
+		{
+			name:       "Recovers after failures",
+			policy:     fastRetryPolicy(3),
+			method:     http.MethodGet,
+			failures:   2,
+			failStatus: http.StatusServiceUnavailable,
+			wantErr:    nil,
+			wantCalls:  3,
+		},
+		{
+			name:       "Gives up after max attempts",
+			policy:     fastRetryPolicy(2),
+			method:     http.MethodGet,
+			failures:   5,
+			failStatus: http.StatusBadGateway,
+			wantErr:    rest.ErrServer,
+			wantCalls:  2,
+		},
+		{
+			name:       "Client errors are not retried",
+			policy:     fastRetryPolicy(3),
+			method:     http.MethodGet,
+			failures:   1,
+			failStatus: http.StatusBadRequest,
+			wantErr:    rest.ErrBadRequest,
+			wantCalls:  1,
+		},
+		{
+			name:       "Non-idempotent methods are not retried by default",
+			policy:     fastRetryPolicy(3),
+			method:     http.MethodPost,
+			failures:   1,
+			failStatus: http.StatusServiceUnavailable,
+			wantErr:    rest.ErrServer,
+			wantCalls:  1,
+		},
+		{
+			name: "Non-idempotent methods are retried on opt-in",
+			policy: func() *rest.RetryPolicy {
+				p := fastRetryPolicy(3)
+				p.RetryNonIdempotent = true
+				return p
+			}(),
+			method:     http.MethodPost,
+			failures:   1,
+			failStatus: http.StatusServiceUnavailable,
+			wantErr:    nil,
+			wantCalls:  2,
+		},
+		{
+			name:       "Requests marked idempotent are retried",
+			policy:     fastRetryPolicy(3),
+			method:     http.MethodPost,
+			ctx:        rest.WithIdempotent(context.Background()),
+			failures:   1,
+			failStatus: http.StatusServiceUnavailable,
+			wantErr:    nil,
+			wantCalls:  2,
+		},
+		{
+			name: "Custom status codes",
+			policy: func() *rest.RetryPolicy {
+				p := fastRetryPolicy(3)
+				p.RetryStatusCodes = []int{http.StatusConflict}
+				return p
+			}(),
+			method:     http.MethodGet,
+			failures:   1,
+			failStatus: http.StatusConflict,
+			wantErr:    nil,
+			wantCalls:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := newFlakyServer(tt.failures, tt.failStatus)
+			defer server.Close()
+
+			c := rest.NewClient(rest.Config{
+				BaseURL:     server.URL,
+				RetryPolicy: tt.policy,
+			})
+
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			err := c.Do(ctx, tt.method, "/", nil, map[string]string{"a": "b"}, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Client.Do() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("expected %d calls, got %d", tt.wantCalls, got)
+			}
+		})
+	}
+}
+
+func TestClient_Do_RetryTransportError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	baseURL := server.URL
+	server.Close()
+
+	calls := 0
+	policy := fastRetryPolicy(3)
+	policy.RetryError = func(_ error) bool {
+		calls++
+		return true
+	}
+
+	c := rest.NewClient(rest.Config{
+		BaseURL:     baseURL,
+		RetryPolicy: policy,
+	})
+
+	if err := c.Do(context.Background(), http.MethodGet, "/", nil, nil, nil); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if calls != 2 {
+		t.Errorf("expected classifier to be called 2 times, got %d", calls)
+	}
+}
+
+func TestClient_Do_RetryContextCanceled(t *testing.T) {
+	server, calls := newFlakyServer(10, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	policy := fastRetryPolicy(10)
+	policy.BaseDelay = time.Hour
+	policy.MaxDelay = time.Hour
+
+	c := rest.NewClient(rest.Config{
+		BaseURL:     server.URL,
+		RetryPolicy: policy,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := c.Do(ctx, http.MethodGet, "/", nil, nil, nil)
+	if !errors.Is(err, rest.ErrServer) {
+		t.Errorf("expected last response error, got %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected 1 call, got %d", got)
+	}
+}
+
+func TestIsIdempotentMethod(t *testing.T) {
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{http.MethodGet, true},
+		{http.MethodPut, true},
+		{http.MethodDelete, true},
+		{http.MethodHead, true},
+		{http.MethodPost, false},
+		{http.MethodPatch, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			if got := rest.IsIdempotentMethod(tt.method); got != tt.want {
+				t.Errorf("IsIdempotentMethod(%s) = %v, want %v", tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"Too many requests", &rest.APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{"Service unavailable", fmt.Errorf("wrapped: %w", &rest.APIError{StatusCode: http.StatusServiceUnavailable}), true},
+		{"Not implemented", &rest.APIError{StatusCode: http.StatusNotImplemented}, false},
+		{"Bad request", &rest.APIError{StatusCode: http.StatusBadRequest}, false},
+		{"Transport error", &url.Error{Op: "Post", URL: "/", Err: errors.New("connection refused")}, true},
+		{"Canceled request", &url.Error{Op: "Post", URL: "/", Err: context.Canceled}, false},
+		{"Deadline exceeded", context.DeadlineExceeded, false},
+		{"Other error", errors.New("invalid message"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rest.IsTransient(tt.err); got != tt.want {
+				t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package sms
+
+// gsm7Basic is the GSM 03.38 default alphabet, excluding the escape character.
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsm7Extension is the GSM 03.38 extension table. Each character takes two
+// septets as it is prefixed with the escape character.
+const gsm7Extension = "\f^{}\\[~]|€"
+
+//nolint:gochecknoglobals // lookup table
+var (
+	gsm7BasicSet     = runeSet(gsm7Basic)
+	gsm7ExtensionSet = runeSet(gsm7Extension)
+)
+
+// transliterations maps common characters outside of the GSM 03.38 alphabet
+// to their closest GSM-7 replacements.
+//
+//nolint:gochecknoglobals // lookup table
+var transliterations = map[rune]string{
+	// Punctuation
+	'‘': "'", '’': "'", '‚': "'", '‛': "'", '′': "'", '`': "'", '´': "'",
+	'“': `"`, '”': `"`, '„': `"`, '‟': `"`, '″': `"`, '«': `"`, '»': `"`,
+	'‐': "-", '‑': "-", '‒': "-", '–': "-", '—': "-", '―': "-", '−': "-",
+	'…': "...", '•': "-", '·': ".", '¦': "|", '‹': "<", '›': ">",
+	'\u00a0': " ", '\u2002': " ", '\u2003': " ", '\u2009': " ", '\u202f': " ", '\t': " ",
+	'\u200b': "", '\u200d': "", '\ufeff': "",
+	'©': "(c)", '®': "(R)", '™': "TM", '×': "x", '÷': "/",
+	// Latin letters
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ā': "A", 'Ą': "A",
+	'á': "a", 'â': "a", 'ã': "a", 'ā': "a", 'ą': "a",
+	'Ć': "C", 'Č': "C", 'ç': "c", 'ć': "c", 'č': "c",
+	'Ď': "D", 'ď': "d", 'Đ': "D", 'đ': "d",
+	'È': "E", 'Ê': "E", 'Ë': "E", 'Ē': "E", 'Ę': "E", 'Ě': "E",
+	'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I", 'í': "i", 'î': "i", 'ï': "i", 'ı': "i",
+	'Ł': "L", 'ł': "l",
+	'Ń': "N", 'Ň': "N", 'ń': "n", 'ň': "n",
+	'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ő': "O", 'ó': "o", 'ô': "o", 'õ': "o", 'ő': "o",
+	'Œ': "OE", 'œ': "oe",
+	'Ř': "R", 'ř': "r",
+	'Ś': "S", 'Š': "S", 'Ş': "S", 'ś': "s", 'š': "s", 'ş': "s",
+	'Ť': "T", 'ť': "t",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ů': "U", 'Ű': "U", 'ú': "u", 'û': "u", 'ů': "u", 'ű': "u",
+	'Ý': "Y", 'Ÿ': "Y", 'ý': "y", 'ÿ': "y",
+	'Ź': "Z", 'Ż': "Z", 'Ž': "Z", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+func runeSet(s string) map[rune]struct{} {
+	set := make(map[rune]struct{}, len(s))
+	for _, r := range s {
+		set[r] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,8 @@
+package sms
+
+import "errors"
+
+var (
+	ErrEmptyMessage = errors.New("message has no content")
+	ErrInvalidData  = errors.New("invalid data message payload")
+)
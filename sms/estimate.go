@@ -0,0 +1,60 @@
+package sms
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+// Rate returns the price of a single segment sent to the phone number.
+type Rate func(phoneNumber string) float64
+
+// FlatRate returns a Rate with the same price for every phone number.
+func FlatRate(price float64) Rate {
+	return func(string) float64 {
+		return price
+	}
+}
+
+// Estimate is the segment estimate of a message.
+type Estimate struct {
+	Info
+
+	PhoneNumbers  []string // Recipients of the message
+	TotalSegments int      // Segments across all recipients
+}
+
+// Cost returns the price of sending the message to all recipients.
+func (e Estimate) Cost(rate Rate) float64 {
+	var total float64
+	for _, phoneNumber := range e.PhoneNumbers {
+		total += rate(phoneNumber) * float64(e.Segments)
+	}
+	return total
+}
+
+// EstimateMessage returns the segment estimate of the message for all of its
+// recipients. Client-side encryption changes the length of the message, so
+// the estimate of an encrypted message isn't accurate.
+func EstimateMessage(message smsgateway.Message) (Estimate, error) {
+	var info Info
+	switch {
+	case message.GetTextMessage() != nil:
+		info = Analyze(message.GetTextMessage().Text)
+	case message.GetDataMessage() != nil:
+		data, err := base64.StdEncoding.DecodeString(message.GetDataMessage().Data)
+		if err != nil {
+			return Estimate{}, fmt.Errorf("%w: %w", ErrInvalidData, err)
+		}
+		info = AnalyzeData(data)
+	default:
+		return Estimate{}, ErrEmptyMessage
+	}
+
+	return Estimate{
+		Info:          info,
+		PhoneNumbers:  message.PhoneNumbers,
+		TotalSegments: info.Segments * len(message.PhoneNumbers),
+	}, nil
+}
@@ -0,0 +1,149 @@
+// Package sms estimates how many SMS segments a message will be split into.
+//
+// Text that fits the GSM 03.38 alphabet is sent in the GSM-7 encoding, any
+// other text requires UCS-2, which reduces the number of characters per
+// segment. Long messages are split into concatenated segments, each of them
+// losing a few characters to the concatenation header.
+package sms
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Encoding is the encoding used to send a message.
+type Encoding string
+
+const (
+	GSM7   Encoding = "GSM-7"  // GSM 03.38 default alphabet, 7 bits per character
+	UCS2   Encoding = "UCS-2"  // UTF-16, 16 bits per code unit
+	Binary Encoding = "Binary" // 8-bit data
+)
+
+const (
+	gsm7SingleSegment   = 160 // septets in a single GSM-7 segment
+	gsm7MultiSegment    = 153 // septets in a concatenated GSM-7 segment
+	ucs2SingleSegment   = 70  // code units in a single UCS-2 segment
+	ucs2MultiSegment    = 67  // code units in a concatenated UCS-2 segment
+	binarySingleSegment = 140 // bytes in a single binary segment
+	binaryMultiSegment  = 134 // bytes in a concatenated binary segment
+)
+
+// Info describes how a message is split into segments.
+type Info struct {
+	Encoding   Encoding // Encoding of the message
+	Length     int      // Length in septets for GSM-7, UTF-16 code units for UCS-2 or bytes for binary data
+	Segments   int      // Number of segments, zero for an empty message
+	PerSegment int      // Budget of a segment in the same units as Length
+	Remaining  int      // Units left in the last segment
+}
+
+// IsGSM7 reports whether the text can be sent in the GSM-7 encoding.
+func IsGSM7(text string) bool {
+	for _, r := range text {
+		if gsm7Cost(r) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Analyze returns the segment information of the text.
+func Analyze(text string) Info {
+	cost := gsm7Cost
+	encoding := GSM7
+	single, multi := gsm7SingleSegment, gsm7MultiSegment
+	if !IsGSM7(text) {
+		cost = utf16Cost
+		encoding = UCS2
+		single, multi = ucs2SingleSegment, ucs2MultiSegment
+	}
+
+	costs := make([]int, 0, utf8.RuneCountInString(text))
+	for _, r := range text {
+		costs = append(costs, cost(r))
+	}
+
+	return split(encoding, costs, single, multi)
+}
+
+// AnalyzeData returns the segment information of binary data.
+func AnalyzeData(data []byte) Info {
+	costs := make([]int, len(data))
+	for i := range costs {
+		costs[i] = 1
+	}
+
+	return split(Binary, costs, binarySingleSegment, binaryMultiSegment)
+}
+
+// Transliterate replaces common characters outside of the GSM 03.38 alphabet,
+// such as typographic quotes, dashes and accented letters, with their closest
+// GSM-7 equivalents. Other characters are kept, so the result may still
+// require UCS-2; use IsGSM7 to check it.
+func Transliterate(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+
+	for _, r := range text {
+		if replacement, ok := transliterations[r]; ok && gsm7Cost(r) == 0 {
+			b.WriteString(replacement)
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// split fills segments with characters of the given costs. A character is
+// never split between two segments.
+func split(encoding Encoding, costs []int, single, multi int) Info {
+	info := Info{Encoding: encoding, Length: 0, Segments: 0, PerSegment: single, Remaining: single}
+	for _, c := range costs {
+		info.Length += c
+	}
+
+	switch {
+	case info.Length == 0:
+		return info
+	case info.Length <= single:
+		info.Segments = 1
+		info.Remaining = single - info.Length
+		return info
+	}
+
+	info.Segments = 1
+	info.PerSegment = multi
+	used := 0
+	for _, c := range costs {
+		if used+c > multi {
+			info.Segments++
+			used = 0
+		}
+		used += c
+	}
+	info.Remaining = multi - used
+
+	return info
+}
+
+// gsm7Cost returns the number of septets of the character or zero if it is
+// not in the GSM 03.38 alphabet.
+func gsm7Cost(r rune) int {
+	if _, ok := gsm7BasicSet[r]; ok {
+		return 1
+	}
+	if _, ok := gsm7ExtensionSet[r]; ok {
+		return 2 //nolint:mnd // escape character and the character itself
+	}
+	return 0
+}
+
+// utf16Cost returns the number of UTF-16 code units of the character.
+func utf16Cost(r rune) int {
+	if r >= 0x10000 {
+		return 2 //nolint:mnd // surrogate pair
+	}
+	return 1
+}
@@ -0,0 +1,225 @@
+package sms_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/android-sms-gateway/client-go/sms"
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected sms.Info
+	}{
+		{
+			name:     "Empty",
+			text:     "",
+			expected: sms.Info{Encoding: sms.GSM7, Length: 0, Segments: 0, PerSegment: 160, Remaining: 160},
+		},
+		{
+			name:     "GSM-7",
+			text:     "Hello World!",
+			expected: sms.Info{Encoding: sms.GSM7, Length: 12, Segments: 1, PerSegment: 160, Remaining: 148},
+		},
+		{
+			name:     "GSM-7 single segment limit",
+			text:     strings.Repeat("a", 160),
+			expected: sms.Info{Encoding: sms.GSM7, Length: 160, Segments: 1, PerSegment: 160, Remaining: 0},
+		},
+		{
+			name:     "GSM-7 concatenated",
+			text:     strings.Repeat("a", 161),
+			expected: sms.Info{Encoding: sms.GSM7, Length: 161, Segments: 2, PerSegment: 153, Remaining: 145},
+		},
+		{
+			name:     "GSM-7 extension table",
+			text:     "€10 {x}",
+			expected: sms.Info{Encoding: sms.GSM7, Length: 10, Segments: 1, PerSegment: 160, Remaining: 150},
+		},
+		{
+			name:     "GSM-7 escape is not split between segments",
+			text:     strings.Repeat("a", 152) + "€",
+			expected: sms.Info{Encoding: sms.GSM7, Length: 154, Segments: 1, PerSegment: 160, Remaining: 6},
+		},
+		{
+			name:     "GSM-7 escape moved to the next segment",
+			text:     strings.Repeat("a", 152) + "€" + strings.Repeat("a", 10),
+			expected: sms.Info{Encoding: sms.GSM7, Length: 164, Segments: 2, PerSegment: 153, Remaining: 141},
+		},
+		{
+			name:     "UCS-2",
+			text:     "Привет",
+			expected: sms.Info{Encoding: sms.UCS2, Length: 6, Segments: 1, PerSegment: 70, Remaining: 64},
+		},
+		{
+			name:     "UCS-2 concatenated",
+			text:     strings.Repeat("я", 71),
+			expected: sms.Info{Encoding: sms.UCS2, Length: 71, Segments: 2, PerSegment: 67, Remaining: 63},
+		},
+		{
+			name:     "UCS-2 surrogate pairs",
+			text:     strings.Repeat("a", 66) + "😀",
+			expected: sms.Info{Encoding: sms.UCS2, Length: 68, Segments: 1, PerSegment: 70, Remaining: 2},
+		},
+		{
+			name:     "UCS-2 surrogate pair is not split between segments",
+			text:     strings.Repeat("a", 66) + "😀" + strings.Repeat("a", 3),
+			expected: sms.Info{Encoding: sms.UCS2, Length: 71, Segments: 2, PerSegment: 67, Remaining: 62},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := sms.Analyze(tt.text); actual != tt.expected {
+				t.Errorf("Analyze() = %+v, expected %+v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAnalyzeData(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		expected sms.Info
+	}{
+		{name: "Single", size: 140, expected: sms.Info{Encoding: sms.Binary, Length: 140, Segments: 1, PerSegment: 140, Remaining: 0}},
+		{name: "Concatenated", size: 141, expected: sms.Info{Encoding: sms.Binary, Length: 141, Segments: 2, PerSegment: 134, Remaining: 127}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := sms.AnalyzeData(make([]byte, tt.size)); actual != tt.expected {
+				t.Errorf("AnalyzeData() = %+v, expected %+v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		isGSM7   bool
+	}{
+		{name: "GSM-7 kept", text: "Café à Ñoño €5", expected: "Café à Ñoño €5", isGSM7: true},
+		{name: "Punctuation", text: "“Wait…” — it’s done", expected: `"Wait..." - it's done`, isGSM7: true},
+		{name: "Accented letters", text: "Łódź Señor Crème brûlée", expected: "Lodz Señor Crème brulée", isGSM7: true},
+		{name: "Unsupported kept", text: "Привет, “мир”", expected: `Привет, "мир"`, isGSM7: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := sms.Transliterate(tt.text)
+			if actual != tt.expected {
+				t.Errorf("Transliterate() = %q, expected %q", actual, tt.expected)
+			}
+			if sms.IsGSM7(actual) != tt.isGSM7 {
+				t.Errorf("IsGSM7() = %v, expected %v", !tt.isGSM7, tt.isGSM7)
+			}
+		})
+	}
+}
+
+func TestEstimateMessage(t *testing.T) {
+	tests := []struct {
+		name          string
+		message       smsgateway.Message
+		segments      int
+		totalSegments int
+		cost          float64
+		expectErr     error
+	}{
+		{
+			name: "Text message",
+			message: smsgateway.Message{
+				TextMessage:  &smsgateway.TextMessage{Text: strings.Repeat("a", 200)},
+				PhoneNumbers: []string{"+79990001234", "+12025550123"},
+			},
+			segments:      2,
+			totalSegments: 4,
+			cost:          0.25*2 + 0.5*2,
+		},
+		{
+			name: "Deprecated message field",
+			message: smsgateway.Message{
+				Message:      "Hello",
+				PhoneNumbers: []string{"+79990001234"},
+			},
+			segments:      1,
+			totalSegments: 1,
+			cost:          0.25,
+		},
+		{
+			name: "Data message",
+			message: smsgateway.Message{
+				DataMessage:  &smsgateway.DataMessage{Data: "SGVsbG8gV29ybGQh", Port: 53739},
+				PhoneNumbers: []string{"+79990001234"},
+			},
+			segments:      1,
+			totalSegments: 1,
+			cost:          0.25,
+		},
+		{
+			name: "Invalid data message",
+			message: smsgateway.Message{
+				DataMessage:  &smsgateway.DataMessage{Data: "not base64", Port: 53739},
+				PhoneNumbers: []string{"+79990001234"},
+			},
+			expectErr: sms.ErrInvalidData,
+		},
+		{
+			name:      "Empty message",
+			message:   smsgateway.Message{PhoneNumbers: []string{"+79990001234"}},
+			expectErr: sms.ErrEmptyMessage,
+		},
+	}
+
+	rate := func(phoneNumber string) float64 {
+		if strings.HasPrefix(phoneNumber, "+1") {
+			return 0.5
+		}
+		return 0.25
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			estimate, err := sms.EstimateMessage(tt.message)
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("EstimateMessage() error = %v, expected %v", err, tt.expectErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if estimate.Segments != tt.segments {
+				t.Errorf("Segments = %d, expected %d", estimate.Segments, tt.segments)
+			}
+			if estimate.TotalSegments != tt.totalSegments {
+				t.Errorf("TotalSegments = %d, expected %d", estimate.TotalSegments, tt.totalSegments)
+			}
+			if cost := estimate.Cost(rate); cost != tt.cost {
+				t.Errorf("Cost() = %v, expected %v", cost, tt.cost)
+			}
+		})
+	}
+}
+
+func TestFlatRate(t *testing.T) {
+	estimate, err := sms.EstimateMessage(smsgateway.Message{
+		TextMessage:  &smsgateway.TextMessage{Text: "Hello"},
+		PhoneNumbers: []string{"+79990001234", "+12025550123", "+442079460018"},
+	})
+	if err != nil {
+		t.Fatalf("EstimateMessage() error = %v", err)
+	}
+
+	if cost := estimate.Cost(sms.FlatRate(0.5)); cost != 1.5 {
+		t.Errorf("Cost() = %v, expected %v", cost, 1.5)
+	}
+}
@@ -0,0 +1,170 @@
+package smsgateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/android-sms-gateway/client-go/rest"
+)
+
+const bearerPrefix = "Bearer "
+
+// Authenticator authenticates outgoing API requests, e.g. by setting the
+// `Authorization` header.
+//
+// An Authenticator may also implement `Validate() error` to be checked by
+// Config.Validate, and `Invalidate(token string)` to be notified when the
+// server rejects a Bearer token, in which case the request is retried once.
+//
+// Implementations must be safe for concurrent use.
+type Authenticator interface {
+	Apply(ctx context.Context, req *http.Request) error
+}
+
+// BasicAuth authenticates requests with Basic credentials.
+type BasicAuth struct {
+	User     string // Username
+	Password string // Password
+}
+
+// Apply sets the Basic `Authorization` header.
+func (a BasicAuth) Apply(_ context.Context, req *http.Request) error {
+	req.SetBasicAuth(a.User, a.Password)
+	return nil
+}
+
+// Validate checks that both username and password are set.
+func (a BasicAuth) Validate() error {
+	if a.User == "" || a.Password == "" {
+		return fmt.Errorf("%w: missing basic auth credentials", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// BearerAuth authenticates requests with a static Bearer token.
+type BearerAuth struct {
+	Token string // Access token
+}
+
+// Apply sets the Bearer `Authorization` header.
+func (a BearerAuth) Apply(_ context.Context, req *http.Request) error {
+	req.Header.Set(authorizationHeader, bearerPrefix+a.Token)
+	return nil
+}
+
+// Validate checks that the token is set.
+func (a BearerAuth) Validate() error {
+	if a.Token == "" {
+		return fmt.Errorf("%w: missing bearer token", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// TokenAuth authenticates requests with Bearer tokens obtained from a
+// TokenSource before every request, which allows tokens to rotate.
+type TokenAuth struct {
+	source TokenSource
+}
+
+// NewTokenAuth creates an Authenticator backed by the token source.
+func NewTokenAuth(source TokenSource) *TokenAuth {
+	return &TokenAuth{source: source}
+}
+
+// Apply sets the Bearer `Authorization` header with a token from the source.
+func (a *TokenAuth) Apply(ctx context.Context, req *http.Request) error {
+	return applyTokenSource(ctx, a.source, req)
+}
+
+// Validate checks that the token source is set and valid, if it supports
+// validation.
+func (a *TokenAuth) Validate() error {
+	if a.source == nil {
+		return fmt.Errorf("%w: missing token source", ErrInvalidConfig)
+	}
+	return validateAuth(a.source)
+}
+
+func applyTokenSource(ctx context.Context, source TokenSource, req *http.Request) error {
+	token, err := source.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	req.Header.Set(authorizationHeader, bearerPrefix+token)
+	return nil
+}
+
+// validateAuth validates an authenticator or token source, if it supports
+// validation.
+func validateAuth(v any) error {
+	if validator, ok := v.(interface{ Validate() error }); ok {
+		//nolint:wrapcheck // validation errors are already descriptive
+		return validator.Validate()
+	}
+	return nil
+}
+
+type appliedAuthKey struct{}
+
+// appliedAuth records the `Authorization` header set by the authenticator.
+type appliedAuth struct {
+	header string
+}
+
+// authEditor applies the authenticator to requests that don't carry an
+// explicit `Authorization` header, e.g. RefreshToken.
+func authEditor(auth Authenticator) rest.RequestEditorFunc {
+	return func(ctx context.Context, req *http.Request) error {
+		if req.Header.Get(authorizationHeader) != "" {
+			return nil
+		}
+
+		if err := auth.Apply(ctx, req); err != nil {
+			return fmt.Errorf("failed to authenticate request: %w", err)
+		}
+
+		if applied, ok := ctx.Value(appliedAuthKey{}).(*appliedAuth); ok {
+			applied.header = req.Header.Get(authorizationHeader)
+		}
+		return nil
+	}
+}
+
+// authRetryMiddleware retries a request once if the server rejects the Bearer
+// token applied by an authenticator that supports invalidation.
+func authRetryMiddleware(auth Authenticator) rest.Middleware {
+	return func(next rest.RoundTripFunc) rest.RoundTripFunc {
+		invalidator, ok := invalidatorOf(auth)
+		if !ok {
+			return next
+		}
+
+		return func(ctx context.Context, req *rest.Request) (*rest.Response, error) {
+			applied := new(appliedAuth)
+			resp, err := next(context.WithValue(ctx, appliedAuthKey{}, applied), req)
+
+			token, isBearer := strings.CutPrefix(applied.header, bearerPrefix)
+			if !isBearer || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+				return resp, err
+			}
+
+			invalidator.Invalidate(token)
+			return next(ctx, req)
+		}
+	}
+}
+
+// invalidatorOf returns the component of the authenticator that supports
+// token invalidation, if any.
+func invalidatorOf(auth Authenticator) (tokenInvalidator, bool) {
+	if tokenAuth, ok := auth.(*TokenAuth); ok {
+		invalidator, isInvalidator := tokenAuth.source.(tokenInvalidator)
+		return invalidator, isInvalidator
+	}
+
+	invalidator, ok := auth.(tokenInvalidator)
+	return invalidator, ok
+}
@@ -0,0 +1,172 @@
+package smsgateway_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+var errVaultUnavailable = errors.New("vault unavailable")
+
+// rotatingAuth mimics credentials read from a file that is rotated on disk.
+type rotatingAuth struct {
+	mu    sync.Mutex
+	token string
+	err   error
+}
+
+func (a *rotatingAuth) Apply(_ context.Context, req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.err != nil {
+		return a.err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+func (a *rotatingAuth) rotate(token string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.token = token
+}
+
+func TestClient_Authenticators(t *testing.T) {
+	tests := []struct {
+		name          string
+		auth          smsgateway.Authenticator
+		authorization string
+	}{
+		{
+			name:          "Basic",
+			auth:          smsgateway.BasicAuth{User: username, Password: password},
+			authorization: authorizationHeader,
+		},
+		{
+			name:          "Bearer",
+			auth:          smsgateway.BearerAuth{Token: "static"},
+			authorization: "Bearer static",
+		},
+		{
+			name:          "Token source",
+			auth:          smsgateway.NewTokenAuth(staticTokenSource("dynamic")),
+			authorization: "Bearer dynamic",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMockServer(mockServerExpectedInput{
+				method:        http.MethodGet,
+				path:          "/devices",
+				authorization: tt.authorization,
+			}, mockServerOutput{
+				code: http.StatusOK,
+				body: `[]`,
+			})
+			defer server.Close()
+
+			client := smsgateway.NewClient(smsgateway.Config{
+				BaseURL:  server.URL,
+				User:     "ignored",
+				Password: "ignored",
+				Token:    "ignored",
+			}.WithAuthenticator(tt.auth))
+
+			if _, err := client.ListDevices(context.Background()); err != nil {
+				t.Errorf("ListDevices() unexpected error = %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_CustomAuthenticator(t *testing.T) {
+	server := newMockServer(mockServerExpectedInput{
+		method:        http.MethodGet,
+		path:          "/devices",
+		authorization: "Bearer second",
+	}, mockServerOutput{
+		code: http.StatusOK,
+		body: `[]`,
+	})
+	defer server.Close()
+
+	auth := &rotatingAuth{token: "first"}
+	client := smsgateway.NewClient(smsgateway.Config{BaseURL: server.URL}.WithAuthenticator(auth))
+
+	if _, err := client.ListDevices(context.Background()); err == nil {
+		t.Error("ListDevices() expected error with outdated credentials")
+	}
+
+	auth.rotate("second")
+	if _, err := client.ListDevices(context.Background()); err != nil {
+		t.Errorf("ListDevices() unexpected error = %v", err)
+	}
+
+	auth.err = errVaultUnavailable
+	if _, err := client.ListDevices(context.Background()); !errors.Is(err, errVaultUnavailable) {
+		t.Errorf("ListDevices() expected authenticator error, got %v", err)
+	}
+}
+
+func TestClient_TokenManagerAuthenticator(t *testing.T) {
+	server := newTokenServer(time.Hour)
+
+	config := testConfig(newTestServer(t, serialized(server.handle)).URL)
+	manager := smsgateway.NewTokenManager(config, smsgateway.TokenRequest{
+		Scopes: []smsgateway.JWTScope{smsgateway.ScopeMessagesRead},
+	})
+	client := smsgateway.NewClient(config.WithAuthenticator(manager))
+
+	token, err := manager.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() unexpected error = %v", err)
+	}
+	delete(server.valid, token)
+
+	if _, err := client.GetState(context.Background(), "123"); err != nil {
+		t.Fatalf("GetState() unexpected error = %v", err)
+	}
+	if got := server.refreshed; got != 1 {
+		t.Errorf("expected 1 refresh, got %d", got)
+	}
+}
+
+func TestClient_NoRetryWithoutInvalidation(t *testing.T) {
+	calls := new(atomic.Int32)
+	server := newMockServer(mockServerExpectedInput{
+		method:        http.MethodGet,
+		path:          "/devices",
+		authorization: "Bearer valid",
+	}, mockServerOutput{
+		code: http.StatusOK,
+		body: `[]`,
+	})
+	defer server.Close()
+
+	counting := smsgateway.NewTokenAuth(countingTokenSource{calls: calls})
+	client := smsgateway.NewClient(smsgateway.Config{BaseURL: server.URL}.WithAuthenticator(counting))
+
+	if _, err := client.ListDevices(context.Background()); err == nil {
+		t.Error("ListDevices() expected error")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected 1 token request, got %d", got)
+	}
+}
+
+type countingTokenSource struct {
+	calls *atomic.Int32
+}
+
+func (s countingTokenSource) Token(context.Context) (string, error) {
+	s.calls.Add(1)
+	return "invalid", nil
+}
@@ -0,0 +1,284 @@
+package smsgateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/rest"
+)
+
+const (
+	MaxRecipientsPerMessage = 100 // Maximum number of phone numbers in a single message
+	DefaultBulkConcurrency  = 4   // Default number of messages sent in parallel by SendBulk
+)
+
+// BulkOptions configures SendBulk.
+type BulkOptions struct {
+	BatchSize         int          // Recipients per message, defaults to and capped at MaxRecipientsPerMessage
+	Concurrency       int          // Messages sent in parallel, defaults to DefaultBulkConcurrency
+	RequestsPerSecond float64      // Client-side limit of send requests, unlimited if zero
+	SendOptions       []SendOption // Options applied to every message
+
+	// Skip reports whether the recipient was handled by a previous run, e.g.
+	// according to the saved checkpoints. Skipped recipients are neither sent
+	// nor included in the report.
+	Skip func(phoneNumber string) bool
+
+	// Checkpoint is called with the results of every finished batch, one call
+	// at a time. Returning an error stops SendBulk; batches in flight are
+	// cancelled.
+	Checkpoint func(results []BulkResult) error
+}
+
+func (o BulkOptions) withDefaults() BulkOptions {
+	if o.BatchSize <= 0 || o.BatchSize > MaxRecipientsPerMessage {
+		o.BatchSize = MaxRecipientsPerMessage
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultBulkConcurrency
+	}
+	return o
+}
+
+// BulkResult is the result of sending a message to a single recipient.
+type BulkResult struct {
+	PhoneNumber string // Recipient as passed to SendBulk
+	MessageID   string // ID of the message the recipient was sent in, empty on failure
+	Err         error  // Send error, nil on success
+	Retryable   bool   // Whether sending again may succeed, e.g. after a server or network error
+}
+
+// BulkReport lists the results of SendBulk in the order of recipients.
+type BulkReport struct {
+	Results []BulkResult
+}
+
+// Failed returns the results of recipients that were not sent.
+func (r BulkReport) Failed() []BulkResult {
+	failed := make([]BulkResult, 0)
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+type bulkBatch struct {
+	offset       int
+	phoneNumbers []string
+}
+
+type bulkBatchResult struct {
+	batch   bulkBatch
+	results []BulkResult
+}
+
+// SendBulk sends the template message to every recipient, split into messages
+// of up to BatchSize phone numbers. The phone numbers of the template are
+// replaced and its ID must be empty, as every message gets its own ID.
+//
+// Failures of individual messages are reported per recipient and don't stop
+// the other messages. An error is returned only when the context is done or
+// the checkpoint fails, along with the results collected so far; recipients
+// that were not sent are reported with the context error.
+//
+// A network error doesn't guarantee that the message wasn't enqueued, so
+// resending recipients with retryable errors may produce duplicates.
+//
+// Requires the ScopeMessagesSend scope.
+func (c *Client) SendBulk(
+	ctx context.Context,
+	recipients []string,
+	template Message,
+	opts BulkOptions,
+) (BulkReport, error) {
+	if template.ID != "" {
+		return BulkReport{}, fmt.Errorf(
+			"failed to send bulk: %w: id must be empty, each message gets its own ID",
+			ErrValidationFailed,
+		)
+	}
+
+	if err := c.preflight(ctx, "SendBulk", ScopeMessagesSend); err != nil {
+		return BulkReport{}, fmt.Errorf("failed to send bulk: %w", err)
+	}
+
+	opts = opts.withDefaults()
+	batches, report := splitBulk(recipients, opts)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan bulkBatch)
+	done := make(chan bulkBatchResult)
+	limiter := newIntervalLimiter(opts.RequestsPerSecond)
+
+	dispatched := 0
+	go func() {
+		defer close(jobs)
+		for _, batch := range batches {
+			select {
+			case jobs <- batch:
+				dispatched++
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	wg := sync.WaitGroup{}
+	for range min(opts.Concurrency, len(batches)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range jobs {
+				done <- bulkBatchResult{batch: batch, results: c.sendBatch(ctx, limiter, template, batch, opts.SendOptions)}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	var checkpointErr error
+	for result := range done {
+		copy(report.Results[result.batch.offset:], result.results)
+
+		if opts.Checkpoint == nil || checkpointErr != nil {
+			continue
+		}
+		if err := opts.Checkpoint(result.results); err != nil {
+			checkpointErr = err
+			cancel()
+		}
+	}
+
+	// Batches that were never dispatched have no results yet.
+	for _, batch := range batches[dispatched:] {
+		copy(report.Results[batch.offset:], batchResults(batch.phoneNumbers, MessageState{}, ctx.Err()))
+	}
+
+	if checkpointErr != nil {
+		return report, fmt.Errorf("failed to send bulk: checkpoint: %w", checkpointErr)
+	}
+	if err := ctx.Err(); err != nil {
+		return report, fmt.Errorf("failed to send bulk: %w", err)
+	}
+
+	return report, nil
+}
+
+// splitBulk splits the recipients that are not skipped into batches and
+// prepares the report for them.
+func splitBulk(recipients []string, opts BulkOptions) ([]bulkBatch, BulkReport) {
+	pending := make([]string, 0, len(recipients))
+	for _, phoneNumber := range recipients {
+		if opts.Skip == nil || !opts.Skip(phoneNumber) {
+			pending = append(pending, phoneNumber)
+		}
+	}
+
+	batches := make([]bulkBatch, 0, (len(pending)+opts.BatchSize-1)/opts.BatchSize)
+	for offset := 0; offset < len(pending); offset += opts.BatchSize {
+		batches = append(batches, bulkBatch{
+			offset:       offset,
+			phoneNumbers: pending[offset:min(offset+opts.BatchSize, len(pending))],
+		})
+	}
+
+	return batches, BulkReport{Results: make([]BulkResult, len(pending))}
+}
+
+func (c *Client) sendBatch(
+	ctx context.Context,
+	limiter *intervalLimiter,
+	template Message,
+	batch bulkBatch,
+	options []SendOption,
+) []BulkResult {
+	if err := limiter.wait(ctx); err != nil {
+		return batchResults(batch.phoneNumbers, MessageState{}, err)
+	}
+
+	message := template
+	message.PhoneNumbers = batch.phoneNumbers
+
+	state, err := c.Send(ctx, message, options...)
+	return batchResults(batch.phoneNumbers, state, err)
+}
+
+func batchResults(phoneNumbers []string, state MessageState, err error) []BulkResult {
+	results := make([]BulkResult, 0, len(phoneNumbers))
+	for _, phoneNumber := range phoneNumbers {
+		result := BulkResult{PhoneNumber: phoneNumber, MessageID: state.ID, Err: err, Retryable: false}
+		if err != nil {
+			result.MessageID = ""
+			result.Retryable = isRetryableSendError(err)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// isRetryableSendError reports whether sending a message again may succeed
+// after err: the message was not sent because the context is done, or sending
+// failed with a transient error. Other errors, e.g. invalid messages, are
+// final.
+func isRetryableSendError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	return rest.IsTransient(err)
+}
+
+// intervalLimiter spaces out events by a fixed interval. A nil limiter doesn't
+// limit anything.
+type intervalLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newIntervalLimiter(perSecond float64) *intervalLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+
+	return &intervalLimiter{
+		mu:       sync.Mutex{},
+		interval: time.Duration(float64(time.Second) / perSecond),
+		next:     time.Time{},
+	}
+}
+
+// wait blocks until the next event is allowed or the context is done.
+func (l *intervalLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,301 @@
+package smsgateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+// bulkServer accepts messages, responding with the given status code to
+// messages addressed to a phone number listed in failures. Requests are handled
+// concurrently to track the number of requests in flight.
+type bulkServer struct {
+	delay    time.Duration
+	failures map[string]int
+
+	mu       sync.Mutex
+	batches  [][]string
+	inFlight atomic.Int32
+	maxPar   atomic.Int32
+}
+
+func newBulkServer(delay time.Duration, failures map[string]int) *bulkServer {
+	return &bulkServer{delay: delay, failures: failures}
+}
+
+func (s *bulkServer) handle(w http.ResponseWriter, r *http.Request) {
+	n := s.inFlight.Add(1)
+	defer s.inFlight.Add(-1)
+	for current := s.maxPar.Load(); n > current; current = s.maxPar.Load() {
+		if s.maxPar.CompareAndSwap(current, n) {
+			break
+		}
+	}
+	time.Sleep(s.delay)
+
+	message := smsgateway.Message{}
+	_ = json.NewDecoder(r.Body).Decode(&message)
+
+	s.mu.Lock()
+	s.batches = append(s.batches, message.PhoneNumbers)
+	s.mu.Unlock()
+
+	for _, phoneNumber := range message.PhoneNumbers {
+		if code, ok := s.failures[phoneNumber]; ok {
+			w.WriteHeader(code)
+			_, _ = w.Write([]byte(`{"message":"failed"}`))
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(smsgateway.MessageState{
+		ID:    "msg-" + message.PhoneNumbers[0],
+		State: smsgateway.ProcessingStatePending,
+	})
+}
+
+func recipients(n int) []string {
+	result := make([]string, 0, n)
+	for i := range n {
+		result = append(result, fmt.Sprintf("+7999%07d", i))
+	}
+	return result
+}
+
+func bulkTemplate() smsgateway.Message {
+	return smsgateway.Message{TextMessage: &smsgateway.TextMessage{Text: "Hello"}}
+}
+
+func TestClient_SendBulk(t *testing.T) {
+	server := newBulkServer(0, nil)
+	client := newTestClient(t, server.handle)
+
+	checkpoints := 0
+	checkpointed := 0
+	report, err := client.SendBulk(context.Background(), recipients(250), bulkTemplate(), smsgateway.BulkOptions{
+		Checkpoint: func(results []smsgateway.BulkResult) error {
+			checkpoints++
+			checkpointed += len(results)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("SendBulk() error = %v", err)
+	}
+
+	if len(server.batches) != 3 {
+		t.Errorf("expected 3 messages, got %d", len(server.batches))
+	}
+	for _, batch := range server.batches {
+		if len(batch) > smsgateway.MaxRecipientsPerMessage {
+			t.Errorf("message has %d recipients", len(batch))
+		}
+	}
+	if checkpoints != 3 || checkpointed != 250 {
+		t.Errorf("expected 3 checkpoints with 250 results, got %d with %d", checkpoints, checkpointed)
+	}
+
+	if len(report.Results) != 250 || len(report.Failed()) != 0 {
+		t.Fatalf("unexpected report: %d results, %d failed", len(report.Results), len(report.Failed()))
+	}
+	for i, result := range report.Results {
+		if expected := recipients(250)[i]; result.PhoneNumber != expected {
+			t.Fatalf("result %d is for %s, expected %s", i, result.PhoneNumber, expected)
+		}
+	}
+	if id := report.Results[150].MessageID; id != "msg-"+recipients(250)[100] {
+		t.Errorf("unexpected message ID %q", id)
+	}
+}
+
+func TestClient_SendBulk_Failures(t *testing.T) {
+	all := recipients(30)
+	server := newBulkServer(0, map[string]int{
+		all[10]: http.StatusInternalServerError,
+		all[20]: http.StatusBadRequest,
+	})
+	client := newTestClient(t, server.handle)
+
+	report, err := client.SendBulk(context.Background(), all, bulkTemplate(), smsgateway.BulkOptions{
+		BatchSize: 10,
+	})
+	if err != nil {
+		t.Fatalf("SendBulk() error = %v", err)
+	}
+
+	tests := []struct {
+		index     int
+		failed    bool
+		retryable bool
+	}{
+		{index: 0, failed: false, retryable: false},
+		{index: 15, failed: true, retryable: true},
+		{index: 25, failed: true, retryable: false},
+	}
+	for _, tt := range tests {
+		result := report.Results[tt.index]
+		if (result.Err != nil) != tt.failed || result.Retryable != tt.retryable {
+			t.Errorf("result %d: error = %v, retryable = %v", tt.index, result.Err, result.Retryable)
+		}
+	}
+	if len(report.Failed()) != 20 {
+		t.Errorf("expected 20 failed recipients, got %d", len(report.Failed()))
+	}
+}
+
+func TestClient_SendBulk_Resume(t *testing.T) {
+	all := recipients(150)
+	server := newBulkServer(0, nil)
+	client := newTestClient(t, server.handle)
+
+	done := make(map[string]bool)
+	for _, phoneNumber := range all[:100] {
+		done[phoneNumber] = true
+	}
+
+	report, err := client.SendBulk(context.Background(), all, bulkTemplate(), smsgateway.BulkOptions{
+		Skip: func(phoneNumber string) bool { return done[phoneNumber] },
+	})
+	if err != nil {
+		t.Fatalf("SendBulk() error = %v", err)
+	}
+
+	if len(server.batches) != 1 || len(report.Results) != 50 || report.Results[0].PhoneNumber != all[100] {
+		t.Errorf("expected only the remaining 50 recipients to be sent, got %d messages, %d results",
+			len(server.batches), len(report.Results))
+	}
+}
+
+func TestClient_SendBulk_CheckpointError(t *testing.T) {
+	server := newBulkServer(0, nil)
+	client := newTestClient(t, server.handle)
+	errCheckpoint := errors.New("disk full")
+
+	report, err := client.SendBulk(context.Background(), recipients(500), bulkTemplate(), smsgateway.BulkOptions{
+		Concurrency: 1,
+		Checkpoint: func([]smsgateway.BulkResult) error {
+			return errCheckpoint
+		},
+	})
+	if !errors.Is(err, errCheckpoint) {
+		t.Fatalf("SendBulk() error = %v, expected %v", err, errCheckpoint)
+	}
+
+	last := report.Results[len(report.Results)-1]
+	if !errors.Is(last.Err, context.Canceled) || !last.Retryable {
+		t.Errorf("expected unsent recipients to be retryable, got %+v", last)
+	}
+	if len(server.batches) == 5 {
+		t.Error("expected sending to stop after the checkpoint error")
+	}
+}
+
+func TestClient_SendBulk_ContextCanceled(t *testing.T) {
+	server := newBulkServer(10*time.Millisecond, nil)
+	client := newTestClient(t, server.handle)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Millisecond)
+	defer cancel()
+
+	report, err := client.SendBulk(ctx, recipients(1000), bulkTemplate(), smsgateway.BulkOptions{
+		Concurrency: 1,
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("SendBulk() error = %v, expected %v", err, context.DeadlineExceeded)
+	}
+	if len(report.Results) != 1000 || len(report.Failed()) < 800 {
+		t.Errorf("expected most recipients to fail, got %d of %d", len(report.Failed()), len(report.Results))
+	}
+}
+
+func TestClient_SendBulk_Limits(t *testing.T) {
+	server := newBulkServer(5*time.Millisecond, nil)
+	client := newTestClient(t, server.handle)
+
+	start := time.Now()
+	_, err := client.SendBulk(context.Background(), recipients(100), bulkTemplate(), smsgateway.BulkOptions{
+		BatchSize:         10,
+		Concurrency:       2,
+		RequestsPerSecond: 200,
+	})
+	if err != nil {
+		t.Fatalf("SendBulk() error = %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 45*time.Millisecond {
+		t.Errorf("expected 10 requests at 200 rps to take at least 45ms, took %v", elapsed)
+	}
+	if maxPar := server.maxPar.Load(); maxPar > 2 {
+		t.Errorf("expected at most 2 requests in flight, got %d", maxPar)
+	}
+}
+
+func TestClient_SendBulk_TemplateID(t *testing.T) {
+	server := newBulkServer(0, nil)
+	client := newTestClient(t, server.handle)
+
+	template := bulkTemplate()
+	template.ID = "campaign"
+
+	_, err := client.SendBulk(context.Background(), recipients(10), template, smsgateway.BulkOptions{})
+	if !errors.Is(err, smsgateway.ErrValidationFailed) || !strings.Contains(err.Error(), "id must be empty") {
+		t.Errorf("SendBulk() error = %v, expected %v", err, smsgateway.ErrValidationFailed)
+	}
+	if len(server.batches) != 0 {
+		t.Errorf("expected no messages, got %d", len(server.batches))
+	}
+}
+
+func TestClient_SendBulk_Retryable(t *testing.T) {
+	errRejected := errors.New("rejected")
+	closed := newTestServer(t, newBulkServer(0, nil).handle)
+	closed.Close()
+
+	tests := []struct {
+		name      string
+		baseURL   string
+		options   []smsgateway.SendOption
+		retryable bool
+	}{
+		{
+			name:    "Local error",
+			baseURL: newTestServer(t, newBulkServer(0, nil).handle).URL,
+			options: []smsgateway.SendOption{smsgateway.WithRecipientsRewriter(func([]string) ([]string, error) {
+				return nil, errRejected
+			})},
+			retryable: false,
+		},
+		{
+			name:      "Transport error",
+			baseURL:   closed.URL,
+			retryable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := newClient(tt.baseURL).SendBulk(context.Background(), recipients(5), bulkTemplate(), smsgateway.BulkOptions{
+				SendOptions: tt.options,
+			})
+			if err != nil {
+				t.Fatalf("SendBulk() error = %v", err)
+			}
+
+			for _, result := range report.Results {
+				if result.Err == nil || result.Retryable != tt.retryable {
+					t.Fatalf("result error = %v, retryable = %v, expected retryable = %v", result.Err, result.Retryable, tt.retryable)
+				}
+			}
+		})
+	}
+}
@@ -2,10 +2,11 @@ package smsgateway
 
 import (
 	"context"
-	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"time"
 
@@ -23,7 +24,9 @@ const BASE_URL = BaseURL
 type Client struct {
 	*rest.Client
 
-	headers map[string]string
+	scopes TokenSource // source of the token for scope preflight checks, nil if disabled
+	strict bool        // validate requests locally before sending them
+	cipher Cipher      // encrypts sent and decrypts received messages, nil if disabled
 }
 
 // NewClient creates a new instance of the API Client.
@@ -32,53 +35,139 @@ func NewClient(config Config) *Client {
 		config.BaseURL = BaseURL
 	}
 
-	headers := make(map[string]string, 1)
-	if config.Token != "" {
-		headers["Authorization"] = "Bearer " + config.Token
-	} else {
-		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(config.User+":"+config.Password))
+	auth := config.authenticator()
+
+	var scopes TokenSource
+	if config.ScopePreflight {
+		scopes = preflightSource(auth)
 	}
 
 	return &Client{
 		Client: rest.NewClient(rest.Config{
-			Client:  config.Client,
-			BaseURL: config.BaseURL,
+			Client:         config.Client,
+			BaseURL:        config.BaseURL,
+			RetryPolicy:    config.RetryPolicy,
+			Middlewares:    append(slices.Clip(config.Middlewares), authRetryMiddleware(auth)),
+			RequestEditors: []rest.RequestEditorFunc{authEditor(auth)},
 		}),
-		headers: headers,
+		scopes: scopes,
+		strict: config.StrictValidation,
+		cipher: config.Cipher,
 	}
 }
 
 // Send enqueues a message for sending.
+//
+// Requires the ScopeMessagesSend scope.
 func (c *Client) Send(ctx context.Context, message Message, options ...SendOption) (MessageState, error) {
 	opts := new(SendOptions).Apply(options...)
+	if opts.recipientsRewriter != nil && !message.IsEncrypted {
+		phoneNumbers, err := opts.recipientsRewriter(slices.Clone(message.PhoneNumbers))
+		if err != nil {
+			return MessageState{}, fmt.Errorf("failed to send message: %w", err)
+		}
+		message.PhoneNumbers = phoneNumbers
+	}
+
+	if opts.idempotent && message.ID == "" {
+		id, err := idempotentID(opts.idempotencyKey, message.PhoneNumbers)
+		if err != nil {
+			return MessageState{}, fmt.Errorf("failed to send message: %w", err)
+		}
+		message.ID = id
+	}
+
+	if err := c.validate(&message); err != nil {
+		return MessageState{}, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	if err := c.preflight(ctx, "Send", ScopeMessagesSend); err != nil {
+		return MessageState{}, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	if c.cipher != nil {
+		encrypted, err := message.Encrypt(c.cipher)
+		if err != nil {
+			return MessageState{}, fmt.Errorf("failed to send message: %w", err)
+		}
+		message = encrypted
+	}
+
 	path := "/messages?" + opts.ToURLValues().Encode()
 	resp := new(MessageState)
 
-	if err := c.Do(ctx, http.MethodPost, path, c.headers, &message, resp); err != nil {
-		return *resp, fmt.Errorf("failed to send message: %w", err)
+	sendCtx := ctx
+	if opts.idempotent {
+		// The server rejects repeated messages with the same ID, so retries
+		// can't produce duplicates.
+		sendCtx = rest.WithIdempotent(ctx)
 	}
 
-	return *resp, nil
+	if err := c.Do(sendCtx, http.MethodPost, path, nil, &message, resp); err != nil {
+		if !opts.idempotent {
+			return *resp, fmt.Errorf("failed to send message: %w", err)
+		}
+		return c.resolveConflict(ctx, message.ID, err)
+	}
+
+	state, err := c.decryptState(*resp)
+	if err != nil {
+		return state, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return state, nil
+}
+
+// resolveConflict returns the state of the message that was already enqueued
+// with the ID if the idempotent send failed with a conflict, or err with the
+// message ID otherwise.
+func (c *Client) resolveConflict(ctx context.Context, id string, err error) (MessageState, error) {
+	if !errors.Is(err, rest.ErrConflict) {
+		return MessageState{ID: id}, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	state, err := c.GetState(ctx, id)
+	if err != nil {
+		return MessageState{ID: id}, fmt.Errorf("failed to send message: %w", err)
+	}
+	return state, nil
 }
 
 // GetState returns message state by ID.
+//
+// Requires the ScopeMessagesRead scope.
 func (c *Client) GetState(ctx context.Context, messageID string) (MessageState, error) {
+	if err := c.preflight(ctx, "GetState", ScopeMessagesRead); err != nil {
+		return MessageState{}, fmt.Errorf("failed to get message state: %w", err)
+	}
+
 	path := fmt.Sprintf("/messages/%s", url.PathEscape(messageID))
 	resp := new(MessageState)
 
-	if err := c.Do(ctx, http.MethodGet, path, c.headers, nil, resp); err != nil {
+	if err := c.Do(ctx, http.MethodGet, path, nil, nil, resp); err != nil {
 		return *resp, fmt.Errorf("failed to get message state: %w", err)
 	}
 
-	return *resp, nil
+	state, err := c.decryptState(*resp)
+	if err != nil {
+		return state, fmt.Errorf("failed to get message state: %w", err)
+	}
+
+	return state, nil
 }
 
 // ListDevices returns registered devices.
+//
+// Requires the ScopeDevicesList scope.
 func (c *Client) ListDevices(ctx context.Context) ([]Device, error) {
+	if err := c.preflight(ctx, "ListDevices", ScopeDevicesList); err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+
 	path := "/devices"
 	var devices []Device
 
-	if err := c.Do(ctx, http.MethodGet, path, c.headers, nil, &devices); err != nil {
+	if err := c.Do(ctx, http.MethodGet, path, nil, nil, &devices); err != nil {
 		return nil, fmt.Errorf("failed to list devices: %w", err)
 	}
 
@@ -86,10 +175,16 @@ func (c *Client) ListDevices(ctx context.Context) ([]Device, error) {
 }
 
 // DeleteDevice removes a device by ID.
+//
+// Requires the ScopeDevicesDelete scope.
 func (c *Client) DeleteDevice(ctx context.Context, id string) error {
+	if err := c.preflight(ctx, "DeleteDevice", ScopeDevicesDelete); err != nil {
+		return fmt.Errorf("failed to delete device: %w", err)
+	}
+
 	path := fmt.Sprintf("/devices/%s", url.PathEscape(id))
 
-	if err := c.Do(ctx, http.MethodDelete, path, c.headers, nil, nil); err != nil {
+	if err := c.Do(ctx, http.MethodDelete, path, nil, nil, nil); err != nil {
 		return fmt.Errorf("failed to delete device: %w", err)
 	}
 
@@ -101,7 +196,7 @@ func (c *Client) CheckHealth(ctx context.Context) (HealthResponse, error) {
 	path := "/health"
 	resp := new(HealthResponse)
 
-	if err := c.Do(ctx, http.MethodGet, path, c.headers, nil, resp); err != nil {
+	if err := c.Do(ctx, http.MethodGet, path, nil, nil, resp); err != nil {
 		return *resp, fmt.Errorf("failed to check health: %w", err)
 	}
 
@@ -110,11 +205,17 @@ func (c *Client) CheckHealth(ctx context.Context) (HealthResponse, error) {
 
 // ExportInbox exports messages via webhooks.
 //
+// Requires the ScopeMessagesExport scope.
+//
 // Deprecated: use RefreshInbox instead.
 func (c *Client) ExportInbox(ctx context.Context, req MessagesExportRequest) error {
+	if err := c.preflight(ctx, "ExportInbox", ScopeMessagesExport); err != nil {
+		return fmt.Errorf("failed to export inbox: %w", err)
+	}
+
 	path := "/inbox/export"
 
-	if err := c.Do(ctx, http.MethodPost, path, c.headers, &req, nil); err != nil {
+	if err := c.Do(ctx, http.MethodPost, path, nil, &req, nil); err != nil {
 		return fmt.Errorf("failed to export inbox: %w", err)
 	}
 
@@ -123,11 +224,17 @@ func (c *Client) ExportInbox(ctx context.Context, req MessagesExportRequest) err
 
 // ListInboxMessages retrieves incoming messages with filtering and pagination.
 // Returns the messages, total count (from X-Total-Count header), and error.
+//
+// Requires the ScopeInboxList scope.
 func (c *Client) ListInboxMessages(ctx context.Context, opts ListInboxOptions) ([]IncomingMessage, int, error) {
+	if err := c.preflight(ctx, "ListInboxMessages", ScopeInboxList); err != nil {
+		return nil, 0, fmt.Errorf("failed to list inbox messages: %w", err)
+	}
+
 	path := "/inbox?" + opts.ToURLValues().Encode()
 	var msgs []IncomingMessage
 
-	hdr, err := c.DoWithResponseHeaders(ctx, http.MethodGet, path, c.headers, nil, &msgs)
+	hdr, err := c.DoWithResponseHeaders(ctx, http.MethodGet, path, nil, nil, &msgs)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list inbox messages: %w", err)
 	}
@@ -140,14 +247,32 @@ func (c *Client) ListInboxMessages(ctx context.Context, opts ListInboxOptions) (
 		}
 	}
 
+	if c.cipher != nil {
+		for i := range msgs {
+			if msgs[i], err = msgs[i].Decrypt(c.cipher); err != nil {
+				return nil, 0, fmt.Errorf("failed to list inbox messages: %w", err)
+			}
+		}
+	}
+
 	return msgs, total, nil
 }
 
 // RefreshInbox requests an inbox messages refresh.
+//
+// Requires the ScopeInboxRefresh scope.
 func (c *Client) RefreshInbox(ctx context.Context, req InboxRefreshRequest) error {
+	if err := c.validate(req); err != nil {
+		return fmt.Errorf("failed to refresh inbox: %w", err)
+	}
+
+	if err := c.preflight(ctx, "RefreshInbox", ScopeInboxRefresh); err != nil {
+		return fmt.Errorf("failed to refresh inbox: %w", err)
+	}
+
 	path := "/inbox/refresh"
 
-	if err := c.Do(ctx, http.MethodPost, path, c.headers, &req, nil); err != nil {
+	if err := c.Do(ctx, http.MethodPost, path, nil, &req, nil); err != nil {
 		return fmt.Errorf("failed to refresh inbox: %w", err)
 	}
 
@@ -156,11 +281,17 @@ func (c *Client) RefreshInbox(ctx context.Context, req InboxRefreshRequest) erro
 
 // ListMessages retrieves messages with filtering and pagination.
 // Returns the messages, total count (from X-Total-Count header), and error.
+//
+// Requires the ScopeMessagesList scope.
 func (c *Client) ListMessages(ctx context.Context, opts ListMessagesOptions) ([]MessageState, int, error) {
+	if err := c.preflight(ctx, "ListMessages", ScopeMessagesList); err != nil {
+		return nil, 0, fmt.Errorf("failed to list messages: %w", err)
+	}
+
 	path := "/messages?" + opts.ToURLValues().Encode()
 	var msgs []MessageState
 
-	hdr, err := c.DoWithResponseHeaders(ctx, http.MethodGet, path, c.headers, nil, &msgs)
+	hdr, err := c.DoWithResponseHeaders(ctx, http.MethodGet, path, nil, nil, &msgs)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list messages: %w", err)
 	}
@@ -173,18 +304,30 @@ func (c *Client) ListMessages(ctx context.Context, opts ListMessagesOptions) ([]
 		}
 	}
 
+	for i := range msgs {
+		if msgs[i], err = c.decryptState(msgs[i]); err != nil {
+			return nil, 0, fmt.Errorf("failed to list messages: %w", err)
+		}
+	}
+
 	return msgs, total, nil
 }
 
 // GetLogs retrieves log entries.
+//
+// Requires the ScopeLogsRead scope.
 func (c *Client) GetLogs(ctx context.Context, from, to time.Time) ([]LogEntry, error) {
+	if err := c.preflight(ctx, "GetLogs", ScopeLogsRead); err != nil {
+		return nil, fmt.Errorf("failed to get logs: %w", err)
+	}
+
 	query := url.Values{}
 	query.Set("from", from.Format(time.RFC3339))
 	query.Set("to", to.Format(time.RFC3339))
 	path := fmt.Sprintf("/logs?%s", query.Encode())
 	var logs []LogEntry
 
-	if err := c.Do(ctx, http.MethodGet, path, c.headers, nil, &logs); err != nil {
+	if err := c.Do(ctx, http.MethodGet, path, nil, nil, &logs); err != nil {
 		return nil, fmt.Errorf("failed to get logs: %w", err)
 	}
 
@@ -192,11 +335,17 @@ func (c *Client) GetLogs(ctx context.Context, from, to time.Time) ([]LogEntry, e
 }
 
 // GetSettings returns current settings.
+//
+// Requires the ScopeSettingsRead scope.
 func (c *Client) GetSettings(ctx context.Context) (DeviceSettings, error) {
+	if err := c.preflight(ctx, "GetSettings", ScopeSettingsRead); err != nil {
+		return DeviceSettings{}, fmt.Errorf("failed to get settings: %w", err)
+	}
+
 	path := settingsPath
 	resp := new(DeviceSettings)
 
-	if err := c.Do(ctx, http.MethodGet, path, c.headers, nil, resp); err != nil {
+	if err := c.Do(ctx, http.MethodGet, path, nil, nil, resp); err != nil {
 		return *resp, fmt.Errorf("failed to get settings: %w", err)
 	}
 
@@ -204,11 +353,21 @@ func (c *Client) GetSettings(ctx context.Context) (DeviceSettings, error) {
 }
 
 // UpdateSettings partially updates settings.
+//
+// Requires the ScopeSettingsWrite scope.
 func (c *Client) UpdateSettings(ctx context.Context, settings DeviceSettings) (DeviceSettings, error) {
+	if err := c.validate(settings); err != nil {
+		return DeviceSettings{}, fmt.Errorf("failed to update settings: %w", err)
+	}
+
+	if err := c.preflight(ctx, "UpdateSettings", ScopeSettingsWrite); err != nil {
+		return DeviceSettings{}, fmt.Errorf("failed to update settings: %w", err)
+	}
+
 	path := settingsPath
 	resp := new(DeviceSettings)
 
-	if err := c.Do(ctx, http.MethodPatch, path, c.headers, &settings, resp); err != nil {
+	if err := c.Do(ctx, http.MethodPatch, path, nil, &settings, resp); err != nil {
 		return *resp, fmt.Errorf("failed to update settings: %w", err)
 	}
 
@@ -216,11 +375,21 @@ func (c *Client) UpdateSettings(ctx context.Context, settings DeviceSettings) (D
 }
 
 // ReplaceSettings replaces all settings.
+//
+// Requires the ScopeSettingsWrite scope.
 func (c *Client) ReplaceSettings(ctx context.Context, settings DeviceSettings) (DeviceSettings, error) {
+	if err := c.validate(settings); err != nil {
+		return DeviceSettings{}, fmt.Errorf("failed to replace settings: %w", err)
+	}
+
+	if err := c.preflight(ctx, "ReplaceSettings", ScopeSettingsWrite); err != nil {
+		return DeviceSettings{}, fmt.Errorf("failed to replace settings: %w", err)
+	}
+
 	path := settingsPath
 	resp := new(DeviceSettings)
 
-	if err := c.Do(ctx, http.MethodPut, path, c.headers, &settings, resp); err != nil {
+	if err := c.Do(ctx, http.MethodPut, path, nil, &settings, resp); err != nil {
 		return *resp, fmt.Errorf("failed to replace settings: %w", err)
 	}
 
@@ -229,11 +398,17 @@ func (c *Client) ReplaceSettings(ctx context.Context, settings DeviceSettings) (
 
 // ListWebhooks returns registered webhooks
 // Returns a slice of Webhook objects or an error if the request fails.
+//
+// Requires the ScopeWebhooksList scope.
 func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	if err := c.preflight(ctx, "ListWebhooks", ScopeWebhooksList); err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
 	path := "/webhooks"
 	resp := []Webhook{}
 
-	if err := c.Do(ctx, http.MethodGet, path, c.headers, nil, &resp); err != nil {
+	if err := c.Do(ctx, http.MethodGet, path, nil, nil, &resp); err != nil {
 		return resp, fmt.Errorf("failed to list webhooks: %w", err)
 	}
 
@@ -242,11 +417,21 @@ func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
 
 // RegisterWebhook registers or replaces a webhook
 // Returns the registered webhook with server-assigned fields or an error if the request fails.
+//
+// Requires the ScopeWebhooksWrite scope.
 func (c *Client) RegisterWebhook(ctx context.Context, webhook Webhook) (Webhook, error) {
+	if err := c.validate(webhook); err != nil {
+		return Webhook{}, fmt.Errorf("failed to register webhook: %w", err)
+	}
+
+	if err := c.preflight(ctx, "RegisterWebhook", ScopeWebhooksWrite); err != nil {
+		return Webhook{}, fmt.Errorf("failed to register webhook: %w", err)
+	}
+
 	path := "/webhooks"
 	resp := new(Webhook)
 
-	if err := c.Do(ctx, http.MethodPost, path, c.headers, &webhook, resp); err != nil {
+	if err := c.Do(ctx, http.MethodPost, path, nil, &webhook, resp); err != nil {
 		return *resp, fmt.Errorf("failed to register webhook: %w", err)
 	}
 
@@ -255,10 +440,16 @@ func (c *Client) RegisterWebhook(ctx context.Context, webhook Webhook) (Webhook,
 
 // DeleteWebhook removes a webhook by ID
 // Returns an error if the deletion fails.
+//
+// Requires the ScopeWebhooksDelete scope.
 func (c *Client) DeleteWebhook(ctx context.Context, webhookID string) error {
+	if err := c.preflight(ctx, "DeleteWebhook", ScopeWebhooksDelete); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
 	path := fmt.Sprintf("/webhooks/%s", url.PathEscape(webhookID))
 
-	if err := c.Do(ctx, http.MethodDelete, path, c.headers, nil, nil); err != nil {
+	if err := c.Do(ctx, http.MethodDelete, path, nil, nil, nil); err != nil {
 		return fmt.Errorf("failed to delete webhook: %w", err)
 	}
 
@@ -267,11 +458,21 @@ func (c *Client) DeleteWebhook(ctx context.Context, webhookID string) error {
 
 // GenerateToken generates a new access token with specified scopes and ttl.
 // Returns the generated token details or an error if the request fails.
+//
+// Requires the ScopeTokensManage scope when authenticated with a JWT.
 func (c *Client) GenerateToken(ctx context.Context, req TokenRequest) (TokenResponse, error) {
+	if err := c.validate(req); err != nil {
+		return TokenResponse{}, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	if err := c.preflight(ctx, "GenerateToken", ScopeTokensManage); err != nil {
+		return TokenResponse{}, fmt.Errorf("failed to generate token: %w", err)
+	}
+
 	path := "/auth/token"
 	resp := new(TokenResponse)
 
-	if err := c.Do(ctx, http.MethodPost, path, c.headers, &req, resp); err != nil {
+	if err := c.Do(ctx, http.MethodPost, path, nil, &req, resp); err != nil {
 		return *resp, fmt.Errorf("failed to generate token: %w", err)
 	}
 
@@ -284,7 +485,7 @@ func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (TokenRe
 	path := "/auth/token/refresh"
 	resp := new(TokenResponse)
 	headers := map[string]string{
-		"Authorization": "Bearer " + refreshToken,
+		authorizationHeader: "Bearer " + refreshToken,
 	}
 
 	if err := c.Do(ctx, http.MethodPost, path, headers, nil, resp); err != nil {
@@ -296,12 +497,44 @@ func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (TokenRe
 
 // RevokeToken revokes an access token with the specified jti (token ID).
 // Returns an error if the revocation fails.
+//
+// Requires the ScopeTokensManage scope.
 func (c *Client) RevokeToken(ctx context.Context, jti string) error {
+	if err := c.preflight(ctx, "RevokeToken", ScopeTokensManage); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
 	path := fmt.Sprintf("/auth/token/%s", url.PathEscape(jti))
 
-	if err := c.Do(ctx, http.MethodDelete, path, c.headers, nil, nil); err != nil {
+	if err := c.Do(ctx, http.MethodDelete, path, nil, nil, nil); err != nil {
 		return fmt.Errorf("failed to revoke token: %w", err)
 	}
 
 	return nil
 }
+
+// validate checks the request locally in strict validation mode: against its
+// `validate` tags and its Validate method, if any.
+func (c *Client) validate(v any) error {
+	if !c.strict {
+		return nil
+	}
+
+	if err := ValidateStruct(v); err != nil {
+		return err
+	}
+
+	if validator, ok := v.(interface{ Validate() error }); ok {
+		//nolint:wrapcheck // validation errors are already descriptive
+		return validator.Validate()
+	}
+	return nil
+}
+
+// decryptState decrypts the message state if encryption is enabled.
+func (c *Client) decryptState(state MessageState) (MessageState, error) {
+	if c.cipher == nil {
+		return state, nil
+	}
+	return state.Decrypt(c.cipher)
+}
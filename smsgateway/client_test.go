@@ -7,9 +7,11 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/android-sms-gateway/client-go/rest"
 	"github.com/android-sms-gateway/client-go/smsgateway"
 )
 
@@ -81,12 +83,47 @@ func newMockServer(input mockServerExpectedInput, output mockServerOutput) *http
 	}))
 }
 
-func newClient(baseURL string) *smsgateway.Client {
-	return smsgateway.NewClient(smsgateway.Config{
+// testConfig returns the config of a client using Basic auth to access the
+// server.
+func testConfig(baseURL string) smsgateway.Config {
+	return smsgateway.Config{
 		BaseURL:  baseURL,
 		User:     username,
 		Password: password,
-	})
+	}
+}
+
+func newClient(baseURL string) *smsgateway.Client {
+	return smsgateway.NewClient(testConfig(baseURL))
+}
+
+// newTestServer starts a server for the handler, closed when the test ends.
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// newTestClient starts a server for the handler and returns a client using
+// Basic auth to access it.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *smsgateway.Client {
+	t.Helper()
+
+	return newClient(newTestServer(t, handler).URL)
+}
+
+// serialized handles requests one at a time, so fake servers can keep their
+// state without locking.
+func serialized(handler http.HandlerFunc) http.HandlerFunc {
+	mu := new(sync.Mutex)
+	return func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		handler(w, r)
+	}
 }
 
 func newJWTClient(baseURL string) *smsgateway.Client {
@@ -312,6 +349,46 @@ func TestClient_GetState(t *testing.T) {
 			t.Error("Expected error, got nil")
 		}
 	})
+
+	// Test case 3: Unknown message
+	t.Run("Not found", func(t *testing.T) {
+		server := newMockServer(mockServerExpectedInput{
+			method: http.MethodGet,
+			path:   "/messages/123",
+		}, mockServerOutput{
+			code: http.StatusNotFound,
+			body: `{"message": "message not found"}`,
+		},
+		)
+		defer server.Close()
+
+		client := newClient(server.URL)
+
+		_, err := client.GetState(context.Background(), "123")
+		if !rest.IsNotFound(err) {
+			t.Errorf("Expected not found error, got %v", err)
+		}
+	})
+
+	// Test case 4: Invalid credentials
+	t.Run("Unauthorized", func(t *testing.T) {
+		server := newMockServer(mockServerExpectedInput{
+			method: http.MethodGet,
+			path:   "/messages/123",
+		}, mockServerOutput{
+			code: http.StatusOK,
+			body: `{"id": "123", "state": "Pending"}`,
+		},
+		)
+		defer server.Close()
+
+		client := newJWTClient(server.URL)
+
+		_, err := client.GetState(context.Background(), "123")
+		if !rest.IsUnauthorized(err) {
+			t.Errorf("Expected unauthorized error, got %v", err)
+		}
+	})
 }
 
 func TestClient_ListWebhooks(t *testing.T) {
@@ -1626,3 +1703,40 @@ func TestClient_RefreshInbox_Negative(t *testing.T) {
 		}
 	})
 }
+
+func TestClient_Middleware(t *testing.T) {
+	server := newMockServer(mockServerExpectedInput{
+		method: http.MethodGet,
+		path:   "/messages/123",
+	}, mockServerOutput{
+		code: http.StatusOK,
+		body: `{"id": "123", "state": "Pending"}`,
+	})
+	defer server.Close()
+
+	var seen []string
+	logging := func(next rest.RoundTripFunc) rest.RoundTripFunc {
+		return func(ctx context.Context, req *rest.Request) (*rest.Response, error) {
+			resp, err := next(ctx, req)
+			if resp != nil {
+				seen = append(seen, req.Method+" "+req.Path+" "+http.StatusText(resp.StatusCode))
+			}
+			return resp, err
+		}
+	}
+
+	client := smsgateway.NewClient(smsgateway.Config{
+		BaseURL:  server.URL,
+		User:     username,
+		Password: password,
+	}.WithMiddleware(logging))
+
+	if _, err := client.GetState(context.Background(), "123"); err != nil {
+		t.Fatalf("GetState() unexpected error = %v", err)
+	}
+
+	want := []string{"GET /messages/123 OK"}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("expected %v, got %v", want, seen)
+	}
+}
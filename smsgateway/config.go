@@ -3,14 +3,27 @@ package smsgateway
 import (
 	"fmt"
 	"net/http"
+	"slices"
+
+	"github.com/android-sms-gateway/client-go/rest"
 )
 
 type Config struct {
-	Client   *http.Client // Optional HTTP Client, defaults to `http.DefaultClient`
-	BaseURL  string       // Optional base URL, defaults to `https://api.sms-gate.app/3rdparty/v1`
-	User     string       // Basic Auth username
-	Password string       // Basic Auth password
-	Token    string       // Bearer token, has priority over Basic Auth
+	Client      *http.Client      // Optional HTTP Client, defaults to `http.DefaultClient`
+	BaseURL     string            // Optional base URL, defaults to `https://api.sms-gate.app/3rdparty/v1`
+	User        string            // Basic Auth username
+	Password    string            // Basic Auth password
+	Token       string            // Bearer token, has priority over Basic Auth
+	TokenSource TokenSource       // Source of Bearer tokens, has priority over Token and Basic Auth
+	RetryPolicy *rest.RetryPolicy // Optional retry policy, requests are not retried by default
+	Middlewares []rest.Middleware // Optional middlewares, applied to every request in the given order
+
+	Authenticator Authenticator // Request authenticator, has priority over all other credentials
+
+	ScopePreflight   bool // Check token scopes before calling methods, see WithScopePreflight
+	StrictValidation bool // Validate requests locally before sending them, see WithStrictValidation
+
+	Cipher Cipher // Optional end-to-end encryption of messages, see WithEncryption
 }
 
 // WithClient sets the HTTP client for the API client.
@@ -43,6 +56,52 @@ func (c Config) WithJWTAuth(token string) Config {
 	return c
 }
 
+// WithTokenSource sets the source of Bearer tokens for the API client.
+// The token is requested before every call, which allows it to rotate,
+// e.g. with a TokenManager.
+// The token source has priority over the static token and Basic Auth.
+func (c Config) WithTokenSource(source TokenSource) Config {
+	c.TokenSource = source
+	return c
+}
+
+// WithAuthenticator sets the request authenticator for the API client.
+// This is useful for custom authentication schemes, e.g. credentials stored in
+// a secret manager or a file rotated on disk.
+// The authenticator has priority over all other credentials.
+func (c Config) WithAuthenticator(auth Authenticator) Config {
+	c.Authenticator = auth
+	return c
+}
+
+// WithScopePreflight enables or disables scope preflight checks.
+// When enabled, each method decodes the configured Bearer token and fails fast
+// with ErrMissingScope if the token lacks the scope the method requires.
+// Tokens that are not JWTs and Basic Auth credentials are not checked.
+func (c Config) WithScopePreflight(enabled bool) Config {
+	c.ScopePreflight = enabled
+	return c
+}
+
+// WithStrictValidation enables local validation of requests.
+// Send, RegisterWebhook, UpdateSettings, ReplaceSettings, GenerateToken and
+// RefreshInbox then check their input against the `validate` tags and
+// Validate methods, and return the validation errors without a network
+// round trip.
+func (c Config) WithStrictValidation() Config {
+	c.StrictValidation = true
+	return c
+}
+
+// WithEncryption enables end-to-end encryption of messages with the cipher,
+// e.g. `encryption.New(passphrase)` with the passphrase set on the device.
+// Send encrypts messages, and Send, GetState, ListMessages and
+// ListInboxMessages decrypt the returned messages.
+func (c Config) WithEncryption(cipher Cipher) Config {
+	c.Cipher = cipher
+	return c
+}
+
 // WithBasicAuth sets the Basic Auth credentials for the API client.
 // This is useful for setting custom Basic Auth credentials for the API client.
 // If the user or password is empty, it defaults to an empty string.
@@ -52,9 +111,45 @@ func (c Config) WithBasicAuth(user, password string) Config {
 	return c
 }
 
+// WithRetryPolicy sets the retry policy for the API client.
+// If the policy is nil, requests are not retried.
+// Use `rest.DefaultRetryPolicy()` for sensible defaults.
+func (c Config) WithRetryPolicy(policy *rest.RetryPolicy) Config {
+	c.RetryPolicy = policy
+	return c
+}
+
+// WithMiddleware appends middlewares to the API client.
+// Middlewares are applied to every request in the order they were added,
+// the first one being the outermost.
+func (c Config) WithMiddleware(middlewares ...rest.Middleware) Config {
+	c.Middlewares = append(slices.Clip(c.Middlewares), middlewares...)
+	return c
+}
+
 func (c Config) Validate() error {
-	if c.User == "" && c.Password == "" && c.Token == "" {
+	switch {
+	case c.Authenticator != nil:
+		return validateAuth(c.Authenticator)
+	case c.TokenSource != nil:
+		return validateAuth(c.TokenSource)
+	case c.User == "" && c.Password == "" && c.Token == "":
 		return fmt.Errorf("%w: missing auth credentials", ErrInvalidConfig)
 	}
 	return nil
 }
+
+// authenticator returns the configured authenticator, falling back to the
+// token source, the static token and Basic Auth in that order.
+func (c Config) authenticator() Authenticator {
+	switch {
+	case c.Authenticator != nil:
+		return c.Authenticator
+	case c.TokenSource != nil:
+		return NewTokenAuth(c.TokenSource)
+	case c.Token != "":
+		return BearerAuth{Token: c.Token}
+	default:
+		return BasicAuth{User: c.User, Password: c.Password}
+	}
+}
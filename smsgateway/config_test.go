@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/android-sms-gateway/client-go/rest"
 	"github.com/android-sms-gateway/client-go/smsgateway"
 )
 
@@ -161,6 +162,38 @@ func TestConfig_WithBasicAuth(t *testing.T) {
 	}
 }
 
+func TestConfig_WithRetryPolicy(t *testing.T) {
+	policy := rest.DefaultRetryPolicy()
+
+	config := smsgateway.Config{}.WithRetryPolicy(policy)
+	if config.RetryPolicy != policy {
+		t.Errorf("WithRetryPolicy() policy = %v, want %v", config.RetryPolicy, policy)
+	}
+
+	config = config.WithRetryPolicy(nil)
+	if config.RetryPolicy != nil {
+		t.Errorf("WithRetryPolicy(nil) policy = %v, want nil", config.RetryPolicy)
+	}
+}
+
+func TestConfig_WithMiddleware(t *testing.T) {
+	noop := func(next rest.RoundTripFunc) rest.RoundTripFunc { return next }
+
+	base := smsgateway.Config{}.WithMiddleware(noop)
+	first := base.WithMiddleware(noop)
+	second := base.WithMiddleware(noop, noop)
+
+	if len(base.Middlewares) != 1 {
+		t.Errorf("WithMiddleware() modified base config, got %d middlewares", len(base.Middlewares))
+	}
+	if len(first.Middlewares) != 2 {
+		t.Errorf("WithMiddleware() expected 2 middlewares, got %d", len(first.Middlewares))
+	}
+	if len(second.Middlewares) != 3 {
+		t.Errorf("WithMiddleware() expected 3 middlewares, got %d", len(second.Middlewares))
+	}
+}
+
 func TestConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -206,6 +239,70 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "valid config with token source",
+			config: smsgateway.Config{
+				TokenSource: smsgateway.NewTokenManager(
+					smsgateway.Config{User: "testuser", Password: "testpass"},
+					smsgateway.TokenRequest{Scopes: []smsgateway.JWTScope{smsgateway.ScopeMessagesSend}},
+				),
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid config with token source without scopes",
+			config: smsgateway.Config{
+				TokenSource: smsgateway.NewTokenManager(
+					smsgateway.Config{User: "testuser", Password: "testpass"},
+					smsgateway.TokenRequest{},
+				),
+			},
+			expectError: true,
+			errorMsg:    "invalid config: missing token scopes",
+		},
+		{
+			name: "valid config with basic authenticator",
+			config: smsgateway.Config{
+				Authenticator: smsgateway.BasicAuth{User: "testuser", Password: "testpass"},
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid config with basic authenticator without password",
+			config: smsgateway.Config{
+				Authenticator: smsgateway.BasicAuth{User: "testuser"},
+			},
+			expectError: true,
+			errorMsg:    "invalid config: missing basic auth credentials",
+		},
+		{
+			name: "invalid config with bearer authenticator without token",
+			config: smsgateway.Config{
+				User:          "testuser",
+				Password:      "testpass",
+				Authenticator: smsgateway.BearerAuth{},
+			},
+			expectError: true,
+			errorMsg:    "invalid config: missing bearer token",
+		},
+		{
+			name: "invalid config with token authenticator without source",
+			config: smsgateway.Config{
+				Authenticator: smsgateway.NewTokenAuth(nil),
+			},
+			expectError: true,
+			errorMsg:    "invalid config: missing token source",
+		},
+		{
+			name: "valid config with token manager authenticator",
+			config: smsgateway.Config{
+				Authenticator: smsgateway.NewTokenManager(
+					smsgateway.Config{User: "testuser", Password: "testpass"},
+					smsgateway.TokenRequest{Scopes: []smsgateway.JWTScope{smsgateway.ScopeMessagesSend}},
+				),
+			},
+			expectError: false,
+		},
 		{
 			name:        "invalid config with no auth credentials",
 			config:      smsgateway.Config{},
@@ -0,0 +1,432 @@
+package smsgateway
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/rest"
+)
+
+// PoolStrategy defines how DevicePool picks a device for a message.
+type PoolStrategy string
+
+const (
+	// PoolRoundRobin cycles through available devices.
+	PoolRoundRobin PoolStrategy = "RoundRobin"
+	// PoolLeastRecentlyUsed picks the device that was picked least recently.
+	PoolLeastRecentlyUsed PoolStrategy = "LeastRecentlyUsed"
+	// PoolWeighted spreads messages across devices in proportion to their
+	// weights, see WithPoolWeights.
+	PoolWeighted PoolStrategy = "Weighted"
+)
+
+const (
+	DefaultPoolRefreshInterval = time.Minute     // Default interval between background refreshes of the device list
+	DefaultPoolMaxLastSeen     = time.Hour       // Default age of LastSeen after which a device is considered offline
+	DefaultPoolMaxFailures     = 3               // Default number of consecutive send failures before a device is suspended
+	DefaultPoolCooldown        = 5 * time.Minute // Default suspension time of a failing device
+)
+
+// DevicePoolOption configures a DevicePool.
+type DevicePoolOption func(*DevicePool)
+
+// WithPoolStrategy sets the strategy used to pick devices. Defaults to
+// PoolRoundRobin.
+func WithPoolStrategy(strategy PoolStrategy) DevicePoolOption {
+	return func(p *DevicePool) {
+		p.strategy = strategy
+	}
+}
+
+// WithPoolWeights sets the device weights for the PoolWeighted strategy.
+// Devices without a weight have a weight of 1, devices with a weight of zero or
+// less are never picked.
+func WithPoolWeights(weights map[string]int) DevicePoolOption {
+	return func(p *DevicePool) {
+		p.weights = weights
+	}
+}
+
+// WithPoolRefreshInterval sets the interval between background refreshes of
+// the device list. Defaults to DefaultPoolRefreshInterval.
+func WithPoolRefreshInterval(d time.Duration) DevicePoolOption {
+	return func(p *DevicePool) {
+		p.refreshInterval = d
+	}
+}
+
+// WithPoolMaxLastSeen sets the age of Device.LastSeen after which a device is
+// considered offline and is not picked. Defaults to DefaultPoolMaxLastSeen,
+// zero disables the check.
+func WithPoolMaxLastSeen(d time.Duration) DevicePoolOption {
+	return func(p *DevicePool) {
+		p.maxLastSeen = d
+	}
+}
+
+// WithPoolFailover sets the number of consecutive send failures after which a
+// device is suspended and the time it stays suspended. Defaults to
+// DefaultPoolMaxFailures and DefaultPoolCooldown.
+func WithPoolFailover(maxFailures int, cooldown time.Duration) DevicePoolOption {
+	return func(p *DevicePool) {
+		p.maxFailures = maxFailures
+		p.cooldown = cooldown
+	}
+}
+
+// WithPoolOnRefreshError sets a function called with errors of background
+// refreshes. The pool keeps the previous device list on errors.
+func WithPoolOnRefreshError(fn func(error)) DevicePoolOption {
+	return func(p *DevicePool) {
+		p.onRefreshError = fn
+	}
+}
+
+type poolDevice struct {
+	Device
+
+	lastUsed  time.Time // last time the device was picked
+	failures  int       // consecutive send failures
+	suspended time.Time // the device is not picked until this time
+	current   int       // current weight of the smooth weighted round-robin
+}
+
+// DevicePool distributes messages across the registered devices.
+//
+// The pool keeps the device list from ListDevices, skipping deleted devices
+// and devices that were not seen recently. Devices that keep failing to send
+// are suspended for a while, and Send fails over to another device.
+//
+// DevicePool is safe for concurrent use.
+type DevicePool struct {
+	client          *Client
+	strategy        PoolStrategy
+	weights         map[string]int
+	refreshInterval time.Duration
+	maxLastSeen     time.Duration
+	maxFailures     int
+	cooldown        time.Duration
+	onRefreshError  func(error)
+	now             func() time.Time
+
+	mu      sync.Mutex
+	devices []*poolDevice // sorted by ID
+	next    int           // round-robin position
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// NewDevicePool creates a DevicePool that sends messages with the client. The
+// pool is empty until Refresh or Start is called.
+func NewDevicePool(client *Client, options ...DevicePoolOption) *DevicePool {
+	p := &DevicePool{
+		client:          client,
+		strategy:        PoolRoundRobin,
+		weights:         nil,
+		refreshInterval: DefaultPoolRefreshInterval,
+		maxLastSeen:     DefaultPoolMaxLastSeen,
+		maxFailures:     DefaultPoolMaxFailures,
+		cooldown:        DefaultPoolCooldown,
+		onRefreshError:  nil,
+		now:             time.Now,
+
+		mu:      sync.Mutex{},
+		devices: nil,
+		next:    0,
+
+		stop: nil,
+		done: nil,
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	if p.refreshInterval <= 0 {
+		p.refreshInterval = DefaultPoolRefreshInterval
+	}
+	if p.maxFailures < 1 {
+		p.maxFailures = 1
+	}
+
+	return p
+}
+
+// Refresh updates the device list. Failure statistics of known devices are
+// kept.
+//
+// Requires the ScopeDevicesList scope.
+func (p *DevicePool) Refresh(ctx context.Context) error {
+	devices, err := p.client.ListDevices(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to refresh device pool: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	known := make(map[string]*poolDevice, len(p.devices))
+	for _, device := range p.devices {
+		known[device.ID] = device
+	}
+
+	p.devices = make([]*poolDevice, 0, len(devices))
+	for _, device := range devices {
+		if device.DeletedAt != nil {
+			continue
+		}
+
+		d, ok := known[device.ID]
+		if !ok {
+			d = &poolDevice{Device: device, lastUsed: time.Time{}, failures: 0, suspended: time.Time{}, current: 0}
+		}
+		d.Device = device
+		p.devices = append(p.devices, d)
+	}
+	slices.SortFunc(p.devices, func(a, b *poolDevice) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return nil
+}
+
+// Start refreshes the device list and keeps refreshing it in the background
+// until ctx is done or Stop is called. Calling Start on a started pool only
+// refreshes the device list.
+func (p *DevicePool) Start(ctx context.Context) error {
+	if err := p.Refresh(ctx); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stop != nil {
+		return nil
+	}
+
+	ctx, p.stop = context.WithCancel(ctx)
+	p.done = make(chan struct{})
+	go p.run(ctx, p.done)
+
+	return nil
+}
+
+// Stop stops the background refresh and waits for it to finish.
+func (p *DevicePool) Stop() {
+	p.mu.Lock()
+	stop, done := p.stop, p.done
+	p.stop, p.done = nil, nil
+	p.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	stop()
+	<-done
+}
+
+func (p *DevicePool) run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(p.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.Refresh(ctx); err != nil && ctx.Err() == nil && p.onRefreshError != nil {
+				p.onRefreshError(err)
+			}
+		}
+	}
+}
+
+// Devices returns the devices that can currently be picked.
+func (p *DevicePool) Devices() []Device {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	available := p.available(nil)
+	devices := make([]Device, 0, len(available))
+	for _, device := range available {
+		devices = append(devices, device.Device)
+	}
+	return devices
+}
+
+// Pick returns the next device according to the strategy. It returns
+// ErrNoDevices if no device can be picked.
+func (p *DevicePool) Pick() (Device, error) {
+	return p.pick(nil)
+}
+
+// ReportSuccess resets the failure count of the device.
+func (p *DevicePool) ReportSuccess(deviceID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if d := p.find(deviceID); d != nil {
+		d.failures = 0
+	}
+}
+
+// ReportFailure records a send failure of the device, e.g. reported by a
+// webhook. The device is suspended after too many consecutive failures.
+func (p *DevicePool) ReportFailure(deviceID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	d := p.find(deviceID)
+	if d == nil {
+		return
+	}
+
+	d.failures++
+	if d.failures >= p.maxFailures {
+		d.failures = 0
+		d.suspended = p.now().Add(p.cooldown)
+	}
+}
+
+// Send sends the message through a device picked from the pool. If sending
+// fails with a network error, the failure is reported and the message is sent
+// through the next device, until every device was tried. Messages enqueued in
+// the Failed state are reported as failures too, but are not sent again.
+//
+// Other errors are returned immediately and don't count as device failures:
+// `429 Too Many Requests` and server error responses are limits and outages of
+// the server, not of a device, and errors like invalid messages would fail on
+// any device. Messages with an explicit DeviceID are sent through that device
+// only.
+//
+// A network error doesn't guarantee that the message wasn't enqueued, so use
+// WithIdempotencyKey to avoid duplicates on failover.
+//
+// Requires the ScopeMessagesSend scope.
+func (p *DevicePool) Send(ctx context.Context, message Message, options ...SendOption) (MessageState, error) {
+	if message.DeviceID != "" {
+		return p.sendThrough(ctx, message, options)
+	}
+
+	tried := make(map[string]struct{})
+	var (
+		lastState MessageState
+		lastErr   error
+	)
+	for {
+		device, err := p.pick(tried)
+		if err != nil {
+			if lastErr != nil {
+				return lastState, lastErr
+			}
+			return MessageState{}, fmt.Errorf("failed to send message: %w", err)
+		}
+		tried[device.ID] = struct{}{}
+
+		message.DeviceID = device.ID
+		lastState, lastErr = p.sendThrough(ctx, message, options)
+		if lastErr == nil || !rest.IsTransportError(lastErr) {
+			return lastState, lastErr
+		}
+	}
+}
+
+func (p *DevicePool) sendThrough(ctx context.Context, message Message, options []SendOption) (MessageState, error) {
+	state, err := p.client.Send(ctx, message, options...)
+	switch {
+	case err == nil && state.State == ProcessingStateFailed:
+		p.ReportFailure(message.DeviceID)
+	case err == nil:
+		p.ReportSuccess(message.DeviceID)
+	case rest.IsTransportError(err):
+		p.ReportFailure(message.DeviceID)
+	}
+	return state, err
+}
+
+func (p *DevicePool) pick(exclude map[string]struct{}) (Device, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	candidates := p.available(exclude)
+	if len(candidates) == 0 {
+		return Device{}, ErrNoDevices
+	}
+
+	var picked *poolDevice
+	switch p.strategy {
+	case PoolLeastRecentlyUsed:
+		picked = candidates[0]
+		for _, d := range candidates[1:] {
+			if d.lastUsed.Before(picked.lastUsed) {
+				picked = d
+			}
+		}
+	case PoolWeighted:
+		total := 0
+		for _, d := range candidates {
+			weight := p.weight(d.ID)
+			total += weight
+			d.current += weight
+			if picked == nil || d.current > picked.current {
+				picked = d
+			}
+		}
+		picked.current -= total
+	case PoolRoundRobin:
+		fallthrough
+	default:
+		picked = candidates[p.next%len(candidates)]
+		p.next++
+	}
+
+	picked.lastUsed = p.now()
+	return picked.Device, nil
+}
+
+// available returns the devices that can be picked, except the excluded ones.
+func (p *DevicePool) available(exclude map[string]struct{}) []*poolDevice {
+	now := p.now()
+
+	available := make([]*poolDevice, 0, len(p.devices))
+	for _, d := range p.devices {
+		if _, ok := exclude[d.ID]; ok {
+			continue
+		}
+		if now.Before(d.suspended) {
+			continue
+		}
+		if p.maxLastSeen > 0 && now.Sub(d.LastSeen) > p.maxLastSeen {
+			continue
+		}
+		if p.strategy == PoolWeighted && p.weight(d.ID) <= 0 {
+			continue
+		}
+		available = append(available, d)
+	}
+	return available
+}
+
+func (p *DevicePool) weight(deviceID string) int {
+	if weight, ok := p.weights[deviceID]; ok {
+		return weight
+	}
+	return 1
+}
+
+func (p *DevicePool) find(deviceID string) *poolDevice {
+	for _, d := range p.devices {
+		if d.ID == deviceID {
+			return d
+		}
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+package smsgateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/rest"
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+const (
+	dropConnection = -1 // failures code closing the connection without a response
+	failedState    = -2 // failures code enqueueing the message in the Failed state
+)
+
+// devicesServer lists the devices and accepts messages, responding with the
+// status code from failures to messages sent through the listed devices. The
+// devices may be replaced while the pool refreshes in the background.
+type devicesServer struct {
+	mu       sync.Mutex
+	devices  []smsgateway.Device
+	failures map[string]int
+	sent     []string
+}
+
+func newDevicesServer(devices ...smsgateway.Device) *devicesServer {
+	return &devicesServer{devices: devices, failures: make(map[string]int)}
+}
+
+func (s *devicesServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Method == http.MethodGet && r.URL.Path == "/devices" {
+		_ = json.NewEncoder(w).Encode(s.devices)
+		return
+	}
+
+	message := smsgateway.Message{}
+	_ = json.NewDecoder(r.Body).Decode(&message)
+	s.sent = append(s.sent, message.DeviceID)
+
+	state := smsgateway.ProcessingStatePending
+	switch code, ok := s.failures[message.DeviceID]; {
+	case !ok:
+	case code == dropConnection:
+		conn, _, _ := http.NewResponseController(w).Hijack()
+		_ = conn.Close()
+		return
+	case code == failedState:
+		state = smsgateway.ProcessingStateFailed
+	default:
+		w.WriteHeader(code)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(smsgateway.MessageState{
+		ID:       "123",
+		DeviceID: message.DeviceID,
+		State:    state,
+	})
+}
+
+// sentThrough returns the devices of the received messages in order.
+func (s *devicesServer) sentThrough() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.sent)
+}
+
+func (s *devicesServer) setDevices(devices ...smsgateway.Device) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.devices = devices
+}
+
+// newTestPool returns a refreshed pool of the devices listed by the server.
+func newTestPool(t *testing.T, server *devicesServer, options ...smsgateway.DevicePoolOption) *smsgateway.DevicePool {
+	t.Helper()
+
+	pool := smsgateway.NewDevicePool(newTestClient(t, server.handle), options...)
+	if err := pool.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	return pool
+}
+
+func onlineDevice(id string) smsgateway.Device {
+	return smsgateway.Device{ID: id, Name: id, LastSeen: time.Now()}
+}
+
+func pickIDs(t *testing.T, pool *smsgateway.DevicePool, n int) []string {
+	t.Helper()
+
+	ids := make([]string, 0, n)
+	for range n {
+		device, err := pool.Pick()
+		if err != nil {
+			t.Fatalf("Pick() error = %v", err)
+		}
+		ids = append(ids, device.ID)
+	}
+	return ids
+}
+
+func TestDevicePool_Pick(t *testing.T) {
+	deleted := time.Now()
+	stale := onlineDevice("stale")
+	stale.LastSeen = time.Now().Add(-2 * time.Hour)
+	removed := onlineDevice("removed")
+	removed.DeletedAt = &deleted
+
+	tests := []struct {
+		name     string
+		options  []smsgateway.DevicePoolOption
+		expected []string
+	}{
+		{
+			name:     "Round robin",
+			expected: []string{"a", "b", "c", "a", "b", "c"},
+		},
+		{
+			name:     "Least recently used",
+			options:  []smsgateway.DevicePoolOption{smsgateway.WithPoolStrategy(smsgateway.PoolLeastRecentlyUsed)},
+			expected: []string{"a", "b", "c", "a", "b", "c"},
+		},
+		{
+			name: "Weighted",
+			options: []smsgateway.DevicePoolOption{
+				smsgateway.WithPoolStrategy(smsgateway.PoolWeighted),
+				smsgateway.WithPoolWeights(map[string]int{"a": 4, "c": 0}),
+			},
+			expected: []string{"a", "a", "b", "a", "a", "a", "a", "b", "a", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newDevicesServer(onlineDevice("c"), onlineDevice("a"), stale, removed, onlineDevice("b"))
+			pool := newTestPool(t, server, tt.options...)
+
+			actual := pickIDs(t, pool, len(tt.expected))
+			for i := range tt.expected {
+				if actual[i] != tt.expected[i] {
+					t.Fatalf("Pick() sequence = %v, expected %v", actual, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestDevicePool_Send(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  map[string]int
+		deviceID  string
+		expected  string
+		sent      []string
+		expectErr error
+	}{
+		{
+			name:     "Picks a device",
+			expected: "a",
+			sent:     []string{"a"},
+		},
+		{
+			name:     "Fails over on network errors",
+			failures: map[string]int{"a": dropConnection},
+			expected: "b",
+			sent:     []string{"a", "b"},
+		},
+		{
+			name:      "Doesn't fail over on server errors",
+			failures:  map[string]int{"a": http.StatusInternalServerError},
+			sent:      []string{"a"},
+			expectErr: rest.ErrServer,
+		},
+		{
+			name:      "Doesn't fail over on rate limits",
+			failures:  map[string]int{"a": http.StatusTooManyRequests},
+			sent:      []string{"a"},
+			expectErr: rest.ErrTooManyRequests,
+		},
+		{
+			name:      "Doesn't fail over on client errors",
+			failures:  map[string]int{"a": http.StatusBadRequest},
+			sent:      []string{"a"},
+			expectErr: rest.ErrBadRequest,
+		},
+		{
+			name:     "Doesn't resend failed messages",
+			failures: map[string]int{"a": failedState},
+			expected: "a",
+			sent:     []string{"a"},
+		},
+		{
+			name:      "Every device failed",
+			failures:  map[string]int{"a": dropConnection, "b": dropConnection},
+			sent:      []string{"a", "b"},
+			expectErr: io.EOF,
+		},
+		{
+			name:      "Explicit device",
+			failures:  map[string]int{"b": http.StatusInternalServerError},
+			deviceID:  "b",
+			sent:      []string{"b"},
+			expectErr: rest.ErrServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newDevicesServer(onlineDevice("a"), onlineDevice("b"))
+			for id, code := range tt.failures {
+				server.failures[id] = code
+			}
+			pool := newTestPool(t, server)
+
+			state, err := pool.Send(context.Background(), smsgateway.Message{
+				DeviceID:     tt.deviceID,
+				TextMessage:  &smsgateway.TextMessage{Text: "Hello"},
+				PhoneNumbers: []string{"+79990001234"},
+			})
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("Send() error = %v, expected %v", err, tt.expectErr)
+			}
+			if state.DeviceID != tt.expected {
+				t.Errorf("Send() device = %q, expected %q", state.DeviceID, tt.expected)
+			}
+
+			if sent := server.sentThrough(); !slices.Equal(sent, tt.sent) {
+				t.Fatalf("sent through %v, expected %v", sent, tt.sent)
+			}
+		})
+	}
+}
+
+func TestDevicePool_Failover(t *testing.T) {
+	server := newDevicesServer(onlineDevice("a"), onlineDevice("b"))
+	pool := newTestPool(t, server, smsgateway.WithPoolFailover(2, time.Hour))
+
+	pool.ReportFailure("a")
+	if actual := pickIDs(t, pool, 2); actual[0] != "a" {
+		t.Errorf("expected a device to stay available after a single failure, got %v", actual)
+	}
+
+	pool.ReportFailure("a")
+	for _, id := range pickIDs(t, pool, 3) {
+		if id == "a" {
+			t.Fatal("expected a suspended device not to be picked")
+		}
+	}
+
+	pool.ReportFailure("b")
+	pool.ReportSuccess("b")
+	pool.ReportFailure("b")
+	if devices := pool.Devices(); len(devices) != 1 || devices[0].ID != "b" {
+		t.Errorf("expected a success to reset failures, got %v", devices)
+	}
+
+	pool.ReportFailure("b")
+	if _, err := pool.Pick(); !errors.Is(err, smsgateway.ErrNoDevices) {
+		t.Errorf("Pick() error = %v, expected %v", err, smsgateway.ErrNoDevices)
+	}
+}
+
+func TestDevicePool_Start(t *testing.T) {
+	server := newDevicesServer(onlineDevice("a"))
+	pool := smsgateway.NewDevicePool(
+		newTestClient(t, server.handle),
+		smsgateway.WithPoolRefreshInterval(5*time.Millisecond),
+	)
+
+	if _, err := pool.Pick(); !errors.Is(err, smsgateway.ErrNoDevices) {
+		t.Fatalf("Pick() error = %v, expected %v", err, smsgateway.ErrNoDevices)
+	}
+
+	if err := pool.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer pool.Stop()
+
+	if devices := pool.Devices(); len(devices) != 1 {
+		t.Fatalf("expected 1 device after start, got %d", len(devices))
+	}
+
+	server.setDevices(onlineDevice("a"), onlineDevice("b"))
+	deadline := time.Now().Add(time.Second)
+	for len(pool.Devices()) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the pool to refresh in the background")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	pool.Stop()
+	server.setDevices()
+	time.Sleep(20 * time.Millisecond)
+	if devices := pool.Devices(); len(devices) != 2 {
+		t.Errorf("expected no refresh after stop, got %d devices", len(devices))
+	}
+}
+
+func TestDevicePool_Send_LocalErrors(t *testing.T) {
+	server := newDevicesServer(onlineDevice("a"), onlineDevice("b"))
+	pool := newTestPool(t, server)
+	errRejected := errors.New("rejected")
+
+	for range smsgateway.DefaultPoolMaxFailures * 2 {
+		_, err := pool.Send(context.Background(), smsgateway.Message{
+			TextMessage:  &smsgateway.TextMessage{Text: "Hello"},
+			PhoneNumbers: []string{"invalid"},
+		}, smsgateway.WithRecipientsRewriter(func([]string) ([]string, error) {
+			return nil, errRejected
+		}))
+		if !errors.Is(err, errRejected) {
+			t.Fatalf("Send() error = %v, expected %v", err, errRejected)
+		}
+	}
+
+	if sent := server.sentThrough(); len(sent) != 0 {
+		t.Errorf("expected no requests, got %d", len(sent))
+	}
+	if devices := pool.Devices(); len(devices) != 2 {
+		t.Errorf("expected local errors not to suspend devices, got %v", devices)
+	}
+}
+
+func TestDevicePool_Send_DeviceFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  map[string]int
+		available int
+	}{
+		{name: "Rate limits", failures: map[string]int{"a": http.StatusTooManyRequests, "b": http.StatusTooManyRequests}, available: 2},
+		{name: "Server errors", failures: map[string]int{"a": http.StatusBadGateway, "b": http.StatusBadGateway}, available: 2},
+		{name: "Network errors", failures: map[string]int{"a": dropConnection, "b": dropConnection}, available: 0},
+		{name: "Failed messages", failures: map[string]int{"a": failedState, "b": failedState}, available: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newDevicesServer(onlineDevice("a"), onlineDevice("b"))
+			server.failures = tt.failures
+			pool := newTestPool(t, server)
+
+			for range smsgateway.DefaultPoolMaxFailures * 2 {
+				_, _ = pool.Send(context.Background(), smsgateway.Message{
+					TextMessage:  &smsgateway.TextMessage{Text: "Hello"},
+					PhoneNumbers: []string{"+79990001234"},
+				})
+			}
+
+			if devices := pool.Devices(); len(devices) != tt.available {
+				t.Errorf("expected %d available devices, got %v", tt.available, devices)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package smsgateway
+
+import (
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix starts every value in the app's encrypted format.
+const encryptedPrefix = "$"
+
+// Cipher encrypts and decrypts message fields, e.g. with the passphrase set in
+// `SettingsEncryption.Passphrase`. See the encryption package for an
+// implementation compatible with the app.
+type Cipher interface {
+	Encrypt(plaintext string) (string, error)
+	Decrypt(ciphertext string) (string, error)
+}
+
+// Encrypt returns a copy of the message with the text or data content and the
+// phone numbers encrypted, and IsEncrypted set. Encrypted messages are
+// returned as is.
+func (m Message) Encrypt(c Cipher) (Message, error) {
+	if m.IsEncrypted {
+		return m, nil
+	}
+
+	var err error
+	if m.Message != "" {
+		if m.Message, err = c.Encrypt(m.Message); err != nil {
+			return m, fmt.Errorf("failed to encrypt message: %w", err)
+		}
+	}
+	if m.TextMessage != nil {
+		text := *m.TextMessage
+		if text.Text, err = c.Encrypt(text.Text); err != nil {
+			return m, fmt.Errorf("failed to encrypt text: %w", err)
+		}
+		m.TextMessage = &text
+	}
+	if m.DataMessage != nil {
+		data := *m.DataMessage
+		if data.Data, err = c.Encrypt(data.Data); err != nil {
+			return m, fmt.Errorf("failed to encrypt data: %w", err)
+		}
+		m.DataMessage = &data
+	}
+	if m.PhoneNumbers, err = transformAll(m.PhoneNumbers, c.Encrypt); err != nil {
+		return m, fmt.Errorf("failed to encrypt phone number: %w", err)
+	}
+
+	m.IsEncrypted = true
+	return m, nil
+}
+
+// Decrypt returns a copy of the message state with the phone numbers and the
+// content, if present, decrypted. States of messages that are not encrypted
+// are returned as is.
+func (s MessageState) Decrypt(c Cipher) (MessageState, error) {
+	if !s.IsEncrypted {
+		return s, nil
+	}
+
+	var err error
+	if s.TextMessage != nil {
+		text := *s.TextMessage
+		if text.Text, err = c.Decrypt(text.Text); err != nil {
+			return s, fmt.Errorf("failed to decrypt text: %w", err)
+		}
+		s.TextMessage = &text
+	}
+	if s.DataMessage != nil {
+		data := *s.DataMessage
+		if data.Data, err = c.Decrypt(data.Data); err != nil {
+			return s, fmt.Errorf("failed to decrypt data: %w", err)
+		}
+		s.DataMessage = &data
+	}
+
+	recipients := make([]RecipientState, len(s.Recipients))
+	for i, recipient := range s.Recipients {
+		if recipient.PhoneNumber, err = decryptIfEncrypted(c, recipient.PhoneNumber); err != nil {
+			return s, fmt.Errorf("failed to decrypt phone number: %w", err)
+		}
+		recipients[i] = recipient
+	}
+	s.Recipients = recipients
+
+	s.IsEncrypted = false
+	return s, nil
+}
+
+// Decrypt returns a copy of the incoming message with the sender, recipient
+// and content preview decrypted, if they are encrypted.
+func (m IncomingMessage) Decrypt(c Cipher) (IncomingMessage, error) {
+	var err error
+	if m.Sender, err = decryptIfEncrypted(c, m.Sender); err != nil {
+		return m, fmt.Errorf("failed to decrypt sender: %w", err)
+	}
+	if m.Recipient != nil {
+		recipient := *m.Recipient
+		if recipient, err = decryptIfEncrypted(c, recipient); err != nil {
+			return m, fmt.Errorf("failed to decrypt recipient: %w", err)
+		}
+		m.Recipient = &recipient
+	}
+	if m.ContentPreview, err = decryptIfEncrypted(c, m.ContentPreview); err != nil {
+		return m, fmt.Errorf("failed to decrypt content: %w", err)
+	}
+
+	return m, nil
+}
+
+// decryptIfEncrypted decrypts values in the encrypted format only, e.g. phone
+// numbers may be hashed instead.
+func decryptIfEncrypted(c Cipher, value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+	return c.Decrypt(value) //nolint:wrapcheck // wrapped by callers
+}
+
+func transformAll(values []string, fn func(string) (string, error)) ([]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+
+	result := make([]string, len(values))
+	for i, value := range values {
+		transformed, err := fn(value)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = transformed
+	}
+	return result, nil
+}
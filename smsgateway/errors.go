@@ -5,5 +5,9 @@ import "errors"
 var (
 	ErrConflictFields   = errors.New("conflict fields")
 	ErrInvalidConfig    = errors.New("invalid config")
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrMessageFailed    = errors.New("message failed")
+	ErrMissingScope     = errors.New("missing scope")
+	ErrNoDevices        = errors.New("no available devices")
 	ErrValidationFailed = errors.New("validation failed")
 )
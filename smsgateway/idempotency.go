@@ -0,0 +1,32 @@
+package smsgateway
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// idempotentIDSize is the number of random or hashed bytes in a message ID
+// generated for idempotent sends, encoded as 22 characters.
+const idempotentIDSize = 16
+
+// idempotentID returns the message ID derived from the key and the phone
+// numbers or a random one if the key is nil.
+func idempotentID(key *string, phoneNumbers []string) (string, error) {
+	if key == nil {
+		id := make([]byte, idempotentIDSize)
+		if _, err := rand.Read(id); err != nil {
+			return "", fmt.Errorf("failed to generate message ID: %w", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(id), nil
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(*key))
+	for _, phoneNumber := range phoneNumbers {
+		hash.Write([]byte{0})
+		hash.Write([]byte(phoneNumber))
+	}
+	return base64.RawURLEncoding.EncodeToString(hash.Sum(nil)[:idempotentIDSize]), nil
+}
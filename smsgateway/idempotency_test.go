@@ -0,0 +1,173 @@
+package smsgateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/rest"
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+// idempotentServer enqueues messages by ID, responding with `409 Conflict` to
+// messages with a known ID. The first failures requests are enqueued but fail
+// with `503 Service Unavailable`, as if the response was lost.
+type idempotentServer struct {
+	failures int
+	sends    int
+	messages map[string]smsgateway.Message
+}
+
+func newIdempotentServer(failures int) *idempotentServer {
+	return &idempotentServer{failures: failures, messages: make(map[string]smsgateway.Message)}
+}
+
+func (s *idempotentServer) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		id := strings.TrimPrefix(r.URL.Path, "/messages/")
+		if _, ok := s.messages[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(smsgateway.MessageState{ID: id, State: smsgateway.ProcessingStateSent})
+		return
+	}
+
+	message := smsgateway.Message{}
+	_ = json.NewDecoder(r.Body).Decode(&message)
+	s.sends++
+
+	if message.ID == "" {
+		message.ID = "generated"
+	}
+	if _, ok := s.messages[message.ID]; ok {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"message":"message already exists"}`))
+		return
+	}
+	s.messages[message.ID] = message
+
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(smsgateway.MessageState{ID: message.ID, State: smsgateway.ProcessingStatePending})
+}
+
+// newRetryingClient returns a client for the handler retrying without delay.
+func newRetryingClient(t *testing.T, handler http.HandlerFunc) *smsgateway.Client {
+	t.Helper()
+
+	policy := rest.DefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+
+	return smsgateway.NewClient(testConfig(newTestServer(t, handler).URL).WithRetryPolicy(policy))
+}
+
+func idempotentMessage() smsgateway.Message {
+	return smsgateway.Message{
+		TextMessage:  &smsgateway.TextMessage{Text: "Hello"},
+		PhoneNumbers: []string{"+79990001234"},
+	}
+}
+
+func TestClient_Send_Idempotency(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		options   []smsgateway.SendOption
+		expected  smsgateway.ProcessingState
+		sends     int
+		expectErr error
+	}{
+		{
+			name:     "Enqueued",
+			options:  []smsgateway.SendOption{smsgateway.WithIdempotency()},
+			expected: smsgateway.ProcessingStatePending,
+			sends:    1,
+		},
+		{
+			name:     "Lost response is retried and resolved",
+			failures: 1,
+			options:  []smsgateway.SendOption{smsgateway.WithIdempotency()},
+			expected: smsgateway.ProcessingStateSent,
+			sends:    2,
+		},
+		{
+			name:      "Not retried without idempotency",
+			failures:  1,
+			sends:     1,
+			expectErr: rest.ErrServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newIdempotentServer(tt.failures)
+			client := newRetryingClient(t, serialized(server.handle))
+
+			state, err := client.Send(context.Background(), idempotentMessage(), tt.options...)
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("Send() error = %v, expected %v", err, tt.expectErr)
+			}
+			if state.State != tt.expected {
+				t.Errorf("Send() state = %q, expected %q", state.State, tt.expected)
+			}
+			if server.sends != tt.sends || len(server.messages) != 1 {
+				t.Errorf("expected %d requests and 1 message, got %d and %d", tt.sends, server.sends, len(server.messages))
+			}
+		})
+	}
+}
+
+func TestClient_Send_IdempotencyKey(t *testing.T) {
+	server := newIdempotentServer(0)
+	client := newRetryingClient(t, serialized(server.handle))
+
+	first, err := client.Send(context.Background(), idempotentMessage(), smsgateway.WithIdempotencyKey("order-1"))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	second, err := client.Send(context.Background(), idempotentMessage(), smsgateway.WithIdempotencyKey("order-1"))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	other, err := client.Send(context.Background(), idempotentMessage(), smsgateway.WithIdempotencyKey("order-2"))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if first.ID == "" || first.ID != second.ID || first.ID == other.ID {
+		t.Errorf("expected a stable ID per key, got %q, %q and %q", first.ID, second.ID, other.ID)
+	}
+	if len(server.messages) != 2 {
+		t.Errorf("expected 2 messages, got %d", len(server.messages))
+	}
+	if _, ok := server.messages[first.ID]; !ok {
+		t.Errorf("expected the message to be sent with ID %q", first.ID)
+	}
+}
+
+func TestClient_Send_IdempotencyFailure(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	message := idempotentMessage()
+	message.ID = "explicit"
+
+	state, err := client.Send(context.Background(), message, smsgateway.WithIdempotencyKey("order-1"))
+	if !errors.Is(err, rest.ErrServer) {
+		t.Fatalf("Send() error = %v, expected %v", err, rest.ErrServer)
+	}
+	if state.ID != "explicit" {
+		t.Errorf("expected the message ID to be returned, got %q", state.ID)
+	}
+}
@@ -0,0 +1,131 @@
+package smsgateway
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const jwtParts = 3
+
+// TokenClaims are the claims of an access token relevant to API clients.
+type TokenClaims struct {
+	ID        string     // unique identifier of the token (jti)
+	Subject   string     // user the token was issued for (sub)
+	Scopes    []JWTScope // scopes the token is valid for
+	ExpiresAt time.Time  // expiration time (exp), zero if not set
+}
+
+// HasScope reports whether the token is valid for the given scope.
+func (c TokenClaims) HasScope(scope JWTScope) bool {
+	return slices.Contains(c.Scopes, scope)
+}
+
+// Expired reports whether the token is expired at the given time.
+func (c TokenClaims) Expired(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && !now.Before(c.ExpiresAt)
+}
+
+type jwtPayload struct {
+	ID        string     `json:"jti"`
+	Subject   string     `json:"sub"`
+	Scopes    []JWTScope `json:"scopes"`
+	ExpiresAt int64      `json:"exp"`
+}
+
+// ParseTokenClaims decodes the claims of an access token.
+//
+// The signature is NOT verified, so the claims must not be trusted for
+// anything but client-side checks, e.g. inspecting the scopes of a token.
+func ParseTokenClaims(token string) (TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != jwtParts {
+		return TokenClaims{}, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return TokenClaims{}, fmt.Errorf("%w: failed to decode claims: %w", ErrInvalidToken, err)
+	}
+
+	payload := new(jwtPayload)
+	if unmarshalErr := json.Unmarshal(data, payload); unmarshalErr != nil {
+		return TokenClaims{}, fmt.Errorf("%w: failed to unmarshal claims: %w", ErrInvalidToken, unmarshalErr)
+	}
+
+	claims := TokenClaims{
+		ID:        payload.ID,
+		Subject:   payload.Subject,
+		Scopes:    payload.Scopes,
+		ExpiresAt: time.Time{},
+	}
+	if payload.ExpiresAt != 0 {
+		claims.ExpiresAt = time.Unix(payload.ExpiresAt, 0)
+	}
+
+	return claims, nil
+}
+
+// MissingScopeError is returned by scope preflight checks when the configured
+// access token is not valid for the scope required by a method.
+type MissingScopeError struct {
+	Operation string   // client method, e.g. "ListWebhooks"
+	Scope     JWTScope // required scope
+}
+
+func (e *MissingScopeError) Error() string {
+	return fmt.Sprintf("%s: %s requires scope %q", ErrMissingScope, e.Operation, e.Scope)
+}
+
+func (e *MissingScopeError) Unwrap() error {
+	return ErrMissingScope
+}
+
+// preflightSource returns the token source used for scope preflight checks,
+// or nil if the authenticator doesn't use Bearer tokens.
+func preflightSource(auth Authenticator) TokenSource {
+	switch a := auth.(type) {
+	case BearerAuth:
+		return staticToken(a.Token)
+	case *TokenAuth:
+		return a.source
+	case TokenSource:
+		return a
+	default:
+		return nil
+	}
+}
+
+type staticToken string
+
+func (t staticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// preflight checks that the access token is valid for the scope required by
+// the operation. Tokens that can't be decoded are left to the server.
+func (c *Client) preflight(ctx context.Context, operation string, scope JWTScope) error {
+	if c.scopes == nil {
+		return nil
+	}
+
+	token, err := c.scopes.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	claims, err := ParseTokenClaims(token)
+	if err != nil {
+		return nil //nolint:nilerr // opaque tokens are checked by the server
+	}
+
+	if !claims.HasScope(scope) {
+		return &MissingScopeError{Operation: operation, Scope: scope}
+	}
+
+	return nil
+}
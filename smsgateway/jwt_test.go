@@ -0,0 +1,198 @@
+package smsgateway_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+func newTestJWT(claims string) string {
+	return "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9." +
+		base64.RawURLEncoding.EncodeToString([]byte(claims)) +
+		".c2lnbmF0dXJl"
+}
+
+func TestParseTokenClaims(t *testing.T) {
+	tests := []struct {
+		name        string
+		token       string
+		expected    smsgateway.TokenClaims
+		expectError bool
+	}{
+		{
+			name:  "Full claims",
+			token: newTestJWT(`{"jti":"abc","sub":"user","exp":1700000000,"scopes":["messages:send","webhooks:list"]}`),
+			expected: smsgateway.TokenClaims{
+				ID:        "abc",
+				Subject:   "user",
+				Scopes:    []smsgateway.JWTScope{smsgateway.ScopeMessagesSend, smsgateway.ScopeWebhooksList},
+				ExpiresAt: time.Unix(1700000000, 0),
+			},
+		},
+		{
+			name:     "Without expiration",
+			token:    newTestJWT(`{"sub":"user"}`),
+			expected: smsgateway.TokenClaims{Subject: "user"},
+		},
+		{
+			name:        "Opaque token",
+			token:       "opaque",
+			expectError: true,
+		},
+		{
+			name:        "Invalid encoding",
+			token:       "a.!!!.c",
+			expectError: true,
+		},
+		{
+			name:        "Invalid JSON",
+			token:       newTestJWT(`not json`),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := smsgateway.ParseTokenClaims(tt.token)
+			if tt.expectError {
+				if !errors.Is(err, smsgateway.ErrInvalidToken) {
+					t.Errorf("expected ErrInvalidToken, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTokenClaims() unexpected error = %v", err)
+			}
+
+			if claims.ID != tt.expected.ID || claims.Subject != tt.expected.Subject ||
+				!claims.ExpiresAt.Equal(tt.expected.ExpiresAt) || len(claims.Scopes) != len(tt.expected.Scopes) {
+				t.Errorf("ParseTokenClaims() = %+v, want %+v", claims, tt.expected)
+			}
+			for _, scope := range tt.expected.Scopes {
+				if !claims.HasScope(scope) {
+					t.Errorf("expected scope %q", scope)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenClaims_Expired(t *testing.T) {
+	now := time.Now()
+
+	if (smsgateway.TokenClaims{}).Expired(now) {
+		t.Error("token without expiration must not expire")
+	}
+	if !(smsgateway.TokenClaims{ExpiresAt: now}).Expired(now) {
+		t.Error("expected token to be expired")
+	}
+	if (smsgateway.TokenClaims{ExpiresAt: now.Add(time.Minute)}).Expired(now) {
+		t.Error("expected token not to be expired")
+	}
+}
+
+func TestClient_ScopePreflight(t *testing.T) {
+	token := newTestJWT(`{"scopes":["messages:send"]}`)
+
+	server := newMockServer(mockServerExpectedInput{
+		method:        http.MethodGet,
+		path:          "/webhooks",
+		authorization: "Bearer " + token,
+	}, mockServerOutput{
+		code: http.StatusOK,
+		body: `[]`,
+	})
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		config    smsgateway.Config
+		wantScope bool
+	}{
+		{
+			name:      "Static token",
+			config:    smsgateway.Config{Token: token, ScopePreflight: true},
+			wantScope: true,
+		},
+		{
+			name:      "Token source",
+			config:    smsgateway.Config{ScopePreflight: true}.WithTokenSource(staticTokenSource(token)),
+			wantScope: true,
+		},
+		{
+			name:      "Disabled",
+			config:    smsgateway.Config{Token: token},
+			wantScope: false,
+		},
+		{
+			name:      "Opaque token",
+			config:    smsgateway.Config{Token: "opaque", ScopePreflight: true},
+			wantScope: false,
+		},
+		{
+			name:      "Basic auth",
+			config:    smsgateway.Config{User: username, Password: password, ScopePreflight: true},
+			wantScope: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := smsgateway.NewClient(tt.config.WithBaseURL(server.URL))
+
+			_, err := client.ListWebhooks(context.Background())
+			if tt.wantScope {
+				var scopeErr *smsgateway.MissingScopeError
+				if !errors.As(err, &scopeErr) || !errors.Is(err, smsgateway.ErrMissingScope) {
+					t.Fatalf("expected MissingScopeError, got %v", err)
+				}
+				if scopeErr.Scope != smsgateway.ScopeWebhooksList || scopeErr.Operation != "ListWebhooks" {
+					t.Errorf("unexpected error details: %+v", scopeErr)
+				}
+				return
+			}
+			if errors.Is(err, smsgateway.ErrMissingScope) {
+				t.Errorf("unexpected preflight error: %v", err)
+			}
+		})
+	}
+}
+
+func TestClient_ScopePreflight_Granted(t *testing.T) {
+	token := newTestJWT(`{"scopes":["webhooks:list"]}`)
+
+	server := newMockServer(mockServerExpectedInput{
+		method:        http.MethodGet,
+		path:          "/webhooks",
+		authorization: "Bearer " + token,
+	}, mockServerOutput{
+		code: http.StatusOK,
+		body: `[]`,
+	})
+	defer server.Close()
+
+	client := smsgateway.NewClient(smsgateway.Config{BaseURL: server.URL, Token: token}.WithScopePreflight(true))
+
+	if _, err := client.ListWebhooks(context.Background()); err != nil {
+		t.Errorf("ListWebhooks() unexpected error = %v", err)
+	}
+}
+
+func TestClient_ScopePreflight_GenerateToken(t *testing.T) {
+	token := newTestJWT(`{"scopes":["messages:send"]}`)
+	client := smsgateway.NewClient(smsgateway.Config{BaseURL: "http://127.0.0.1:0", Token: token}.WithScopePreflight(true))
+
+	_, err := client.GenerateToken(context.Background(), smsgateway.TokenRequest{
+		Scopes: []string{string(smsgateway.ScopeMessagesSend)},
+	})
+
+	var scopeErr *smsgateway.MissingScopeError
+	if !errors.As(err, &scopeErr) || scopeErr.Scope != smsgateway.ScopeTokensManage || scopeErr.Operation != "GenerateToken" {
+		t.Fatalf("expected MissingScopeError for GenerateToken, got %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package smsgateway
+
+import (
+	"context"
+	"errors"
+)
+
+// DefaultPageSize is the page size used by pagination helpers when the options
+// don't set Limit.
+const DefaultPageSize = 100
+
+// ErrStopIteration can be returned by a pagination callback to stop iterating
+// without an error.
+var ErrStopIteration = errors.New("stop iteration")
+
+// pageFetcher fetches a single page and the total number of items.
+type pageFetcher[T any] func(ctx context.Context, offset, limit int) ([]T, int, error)
+
+// AllMessages calls fn for every message matching the options, walking all
+// pages starting at opts.Offset with opts.Limit items per page.
+//
+// Messages shifted to the next page by insertions during pagination are
+// reported only once. Iteration stops at the first error returned by fn;
+// return ErrStopIteration to stop without an error.
+func (c *Client) AllMessages(ctx context.Context, opts ListMessagesOptions, fn func(MessageState) error) error {
+	return paginate(ctx, opts.Offset, opts.Limit, c.messagesFetcher(opts), messageStateID, fn)
+}
+
+// AllMessagesChan is like AllMessages but delivers messages over a channel.
+//
+// The messages channel is closed when iteration is complete. The error channel
+// then receives at most one error and is closed. Cancel ctx to stop early.
+func (c *Client) AllMessagesChan(ctx context.Context, opts ListMessagesOptions) (<-chan MessageState, <-chan error) {
+	return paginateChan(ctx, opts.Offset, opts.Limit, c.messagesFetcher(opts), messageStateID)
+}
+
+// AllInboxMessages calls fn for every incoming message matching the options,
+// walking all pages starting at opts.Offset with opts.Limit items per page.
+//
+// Messages shifted to the next page by insertions during pagination are
+// reported only once. Iteration stops at the first error returned by fn;
+// return ErrStopIteration to stop without an error.
+func (c *Client) AllInboxMessages(ctx context.Context, opts ListInboxOptions, fn func(IncomingMessage) error) error {
+	return paginate(ctx, opts.Offset, opts.Limit, c.inboxFetcher(opts), incomingMessageID, fn)
+}
+
+// AllInboxMessagesChan is like AllInboxMessages but delivers messages over a
+// channel.
+//
+// The messages channel is closed when iteration is complete. The error channel
+// then receives at most one error and is closed. Cancel ctx to stop early.
+func (c *Client) AllInboxMessagesChan(
+	ctx context.Context,
+	opts ListInboxOptions,
+) (<-chan IncomingMessage, <-chan error) {
+	return paginateChan(ctx, opts.Offset, opts.Limit, c.inboxFetcher(opts), incomingMessageID)
+}
+
+func (c *Client) messagesFetcher(opts ListMessagesOptions) pageFetcher[MessageState] {
+	return func(ctx context.Context, offset, limit int) ([]MessageState, int, error) {
+		opts.Offset, opts.Limit = &offset, &limit
+		return c.ListMessages(ctx, opts)
+	}
+}
+
+func (c *Client) inboxFetcher(opts ListInboxOptions) pageFetcher[IncomingMessage] {
+	return func(ctx context.Context, offset, limit int) ([]IncomingMessage, int, error) {
+		opts.Offset, opts.Limit = &offset, &limit
+		return c.ListInboxMessages(ctx, opts)
+	}
+}
+
+func messageStateID(m MessageState) string {
+	return m.ID
+}
+
+func incomingMessageID(m IncomingMessage) string {
+	return m.ID
+}
+
+// paginate walks pages until the total count is reached or an empty page is
+// returned. Without a total, it stops at the first short page. The total is
+// re-read on every page, so items inserted during pagination are taken into
+// account.
+func paginate[T any](
+	ctx context.Context,
+	offsetOpt, limitOpt *int,
+	fetch pageFetcher[T],
+	id func(T) string,
+	fn func(T) error,
+) error {
+	offset, limit := 0, DefaultPageSize
+	if offsetOpt != nil {
+		offset = *offsetOpt
+	}
+	if limitOpt != nil && *limitOpt > 0 {
+		limit = *limitOpt
+	}
+
+	seen := make(map[string]struct{})
+	for {
+		if err := ctx.Err(); err != nil {
+			return err //nolint:wrapcheck // context errors are returned as is
+		}
+
+		page, total, err := fetch(ctx, offset, limit)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range page {
+			key := id(item)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+
+			if fnErr := fn(item); fnErr != nil {
+				if errors.Is(fnErr, ErrStopIteration) {
+					return nil
+				}
+				return fnErr
+			}
+		}
+
+		offset += len(page)
+		if len(page) == 0 {
+			return nil
+		}
+		if total > 0 {
+			// The server may return fewer items than the limit per page.
+			if offset >= total {
+				return nil
+			}
+			continue
+		}
+		if len(page) < limit {
+			return nil
+		}
+	}
+}
+
+// paginateChan runs paginate in a goroutine and delivers items over a channel.
+func paginateChan[T any](
+	ctx context.Context,
+	offsetOpt, limitOpt *int,
+	fetch pageFetcher[T],
+	id func(T) string,
+) (<-chan T, <-chan error) {
+	items := make(chan T)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+
+		err := paginate(ctx, offsetOpt, limitOpt, fetch, id, func(item T) error {
+			select {
+			case items <- item:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		close(items)
+
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return items, errs
+}
@@ -0,0 +1,194 @@
+package smsgateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+var errCallback = errors.New("callback failed")
+
+// pagedServer serves newest-first items and optionally inserts new items at the
+// head after serving the first page, like a busy gateway would. Pages are
+// capped at maxPage items if set.
+type pagedServer struct {
+	ids      []string
+	inserts  int
+	maxPage  int
+	requests int
+}
+
+func newPagedServer(count, inserts int) *pagedServer {
+	s := &pagedServer{inserts: inserts}
+	for i := count; i > 0; i-- {
+		s.ids = append(s.ids, strconv.Itoa(i))
+	}
+	return s
+}
+
+func (s *pagedServer) handle(w http.ResponseWriter, r *http.Request) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if s.maxPage > 0 {
+		limit = min(limit, s.maxPage)
+	}
+
+	end := min(offset+limit, len(s.ids))
+	start := min(offset, end)
+
+	page := make([]map[string]any, 0, end-start)
+	for _, id := range s.ids[start:end] {
+		page = append(page, map[string]any{"id": id})
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(s.ids)))
+	_ = json.NewEncoder(w).Encode(page)
+
+	s.requests++
+	if s.requests == 1 {
+		for range s.inserts {
+			s.ids = append([]string{fmt.Sprintf("new-%d", len(s.ids))}, s.ids...)
+		}
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestClient_AllMessages(t *testing.T) {
+	tests := []struct {
+		name     string
+		count    int
+		inserts  int
+		maxPage  int
+		limit    *int
+		expected int
+		requests int
+	}{
+		{name: "Empty", count: 0, limit: intPtr(10), expected: 0, requests: 1},
+		{name: "Single page", count: 5, limit: intPtr(10), expected: 5, requests: 1},
+		{name: "Exact pages", count: 20, limit: intPtr(10), expected: 20, requests: 2},
+		{name: "Partial last page", count: 25, limit: intPtr(10), expected: 25, requests: 3},
+		{name: "Default page size", count: 150, limit: nil, expected: 150, requests: 2},
+		{name: "Insertions while paginating", count: 25, inserts: 3, limit: intPtr(10), expected: 25, requests: 3},
+		{name: "Server caps page size", count: 250, maxPage: 100, limit: intPtr(500), expected: 250, requests: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newPagedServer(tt.count, tt.inserts)
+			server.maxPage = tt.maxPage
+			client := newTestClient(t, serialized(server.handle))
+
+			seen := map[string]int{}
+			err := client.AllMessages(
+				context.Background(),
+				smsgateway.ListMessagesOptions{Limit: tt.limit},
+				func(m smsgateway.MessageState) error {
+					seen[m.ID]++
+					return nil
+				},
+			)
+			if err != nil {
+				t.Fatalf("AllMessages() unexpected error = %v", err)
+			}
+
+			if len(seen) != tt.expected {
+				t.Errorf("expected %d messages, got %d", tt.expected, len(seen))
+			}
+			for id, n := range seen {
+				if n > 1 {
+					t.Errorf("message %s reported %d times", id, n)
+				}
+			}
+			if server.requests != tt.requests {
+				t.Errorf("expected %d requests, got %d", tt.requests, server.requests)
+			}
+		})
+	}
+}
+
+func TestClient_AllMessages_Stop(t *testing.T) {
+	client := newTestClient(t, serialized(newPagedServer(25, 0).handle))
+	opts := smsgateway.ListMessagesOptions{Limit: intPtr(10)}
+
+	count := 0
+	err := client.AllMessages(context.Background(), opts, func(smsgateway.MessageState) error {
+		count++
+		if count == 12 {
+			return smsgateway.ErrStopIteration
+		}
+		return nil
+	})
+	if err != nil || count != 12 {
+		t.Errorf("expected to stop after 12 messages without error, got %d, %v", count, err)
+	}
+
+	err = client.AllMessages(context.Background(), opts, func(smsgateway.MessageState) error {
+		return errCallback
+	})
+	if !errors.Is(err, errCallback) {
+		t.Errorf("expected callback error, got %v", err)
+	}
+}
+
+func TestClient_AllInboxMessagesChan(t *testing.T) {
+	client := newTestClient(t, serialized(newPagedServer(25, 0).handle))
+
+	msgs, errs := client.AllInboxMessagesChan(context.Background(), smsgateway.ListInboxOptions{Limit: intPtr(10)})
+
+	count := 0
+	for range msgs {
+		count++
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("AllInboxMessagesChan() unexpected error = %v", err)
+	}
+	if count != 25 {
+		t.Errorf("expected 25 messages, got %d", count)
+	}
+}
+
+func TestClient_AllMessagesChan_Cancel(t *testing.T) {
+	client := newTestClient(t, serialized(newPagedServer(25, 0).handle))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msgs, errs := client.AllMessagesChan(ctx, smsgateway.ListMessagesOptions{Limit: intPtr(10)})
+
+	<-msgs
+	cancel()
+	for range msgs {
+	}
+
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestClient_AllMessages_Error(t *testing.T) {
+	server := newMockServer(mockServerExpectedInput{
+		method: http.MethodGet,
+		path:   "/messages",
+	}, mockServerOutput{
+		code: http.StatusInternalServerError,
+	})
+	defer server.Close()
+
+	client := newClient(server.URL)
+
+	err := client.AllMessages(context.Background(), smsgateway.ListMessagesOptions{}, func(smsgateway.MessageState) error {
+		return nil
+	})
+	if err == nil {
+		t.Error("AllMessages() expected error")
+	}
+}
@@ -11,6 +11,9 @@ type SendOption func(*SendOptions)
 type SendOptions struct {
 	skipPhoneValidation *bool
 	deviceActiveWithin  *uint
+	recipientsRewriter  func([]string) ([]string, error)
+	idempotent          bool
+	idempotencyKey      *string
 }
 
 func (o *SendOptions) Apply(options ...SendOption) *SendOptions {
@@ -50,6 +53,43 @@ func WithDeviceActiveWithin(hours uint) SendOption {
 	}
 }
 
+// WithRecipientsRewriter returns a SendOption that replaces the recipients of
+// a message with the result of fn before the message is validated and sent.
+// An error returned by fn aborts sending. Messages that are already encrypted
+// are sent as is.
+func WithRecipientsRewriter(fn func(phoneNumbers []string) ([]string, error)) SendOption {
+	return func(o *SendOptions) {
+		o.recipientsRewriter = fn
+	}
+}
+
+// WithIdempotency returns a SendOption that makes Send safe to retry. A random
+// message ID is generated before the first attempt, unless the message has
+// one, so the request can be retried on network and server errors. A
+// `409 Conflict` response for that ID means the message was already
+// enqueued: Send fetches and returns its state with GetState, which requires
+// the ScopeMessagesRead scope.
+//
+// If the request fails, the returned state holds the message ID, which can be
+// used to send the message again or to check its state.
+func WithIdempotency() SendOption {
+	return func(o *SendOptions) {
+		o.idempotent = true
+	}
+}
+
+// WithIdempotencyKey is like WithIdempotency, but the message ID is derived
+// from the key and the recipients, so sending the same message with the same
+// key again, e.g. after a timeout or a crash, returns the state of the
+// message sent first instead of sending a duplicate. An explicit message ID
+// takes precedence over the key.
+func WithIdempotencyKey(key string) SendOption {
+	return func(o *SendOptions) {
+		o.idempotent = true
+		o.idempotencyKey = &key
+	}
+}
+
 // ListInboxOptions holds optional filters for listing inbox messages.
 type ListInboxOptions struct {
 	Type     *IncomingMessageType
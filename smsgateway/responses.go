@@ -1,5 +1,7 @@
 package smsgateway
 
+import "github.com/android-sms-gateway/client-go/rest"
+
 // ErrorResponse represents a response to a request in case of an error.
 //
 // Message is an error message.
@@ -7,8 +9,4 @@ package smsgateway
 // Code is an error code, which is omitted if not specified.
 //
 // Data is an error context, which is omitted if not specified.
-type ErrorResponse struct {
-	Message string `json:"message"        example:"An error occurred"` // Error message
-	Code    int32  `json:"code,omitempty"`                             // Error code
-	Data    any    `json:"data,omitempty"`                             // Error context
-}
+type ErrorResponse = rest.ErrorResponse
@@ -0,0 +1,186 @@
+package smsgateway
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	minSimNumber = 1 // first SIM slot number
+	maxSimNumber = 3 // last SIM slot number
+)
+
+// SimCandidate is a SIM card of a device that a message can be sent through.
+type SimCandidate struct {
+	Device  Device
+	SimCard SimCard
+}
+
+// SimRule narrows down or reorders the candidates for a message. Rules are
+// preferences: if a rule returns no candidates, the previous ones are kept.
+type SimRule func(message Message, candidates []SimCandidate) []SimCandidate
+
+// RouteByPrefix returns a SimRule that keeps the SIM cards of the carrier
+// assigned to the destination prefix, e.g. `{"+7": "MTS", "+1": "T-Mobile"}`.
+// The longest prefix matching the first phone number of the message wins.
+// Carrier names are compared case-insensitively.
+func RouteByPrefix(carriers map[string]string) SimRule {
+	return func(message Message, candidates []SimCandidate) []SimCandidate {
+		if len(message.PhoneNumbers) == 0 {
+			return candidates
+		}
+
+		number := strings.TrimPrefix(message.PhoneNumbers[0], "+")
+		var carrier, match string
+		for prefix, name := range carriers {
+			trimmed := strings.TrimPrefix(prefix, "+")
+			if strings.HasPrefix(number, trimmed) && len(trimmed) > len(match) {
+				carrier, match = name, trimmed
+			}
+		}
+		if carrier == "" {
+			return candidates
+		}
+
+		return filterCarrier(candidates, carrier)
+	}
+}
+
+// PreferCarrier returns a SimRule that keeps the SIM cards of the first
+// carrier in the list that is available.
+func PreferCarrier(carriers ...string) SimRule {
+	return func(_ Message, candidates []SimCandidate) []SimCandidate {
+		for _, carrier := range carriers {
+			if preferred := filterCarrier(candidates, carrier); len(preferred) > 0 {
+				return preferred
+			}
+		}
+		return candidates
+	}
+}
+
+// BalanceSlots returns a SimRule that rotates the candidates on every call,
+// so messages are spread across the remaining SIM cards. It is usually the
+// last rule.
+func BalanceSlots() SimRule {
+	counter := new(atomic.Uint64)
+	return func(_ Message, candidates []SimCandidate) []SimCandidate {
+		if len(candidates) == 0 {
+			return candidates
+		}
+
+		offset := int((counter.Add(1) - 1) % uint64(len(candidates)))
+		rotated := make([]SimCandidate, 0, len(candidates))
+		rotated = append(rotated, candidates[offset:]...)
+		return append(rotated, candidates[:offset]...)
+	}
+}
+
+func filterCarrier(candidates []SimCandidate, carrier string) []SimCandidate {
+	filtered := make([]SimCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if name := candidate.SimCard.CarrierName; name != nil && strings.EqualFold(*name, carrier) {
+			filtered = append(filtered, candidate)
+		}
+	}
+	return filtered
+}
+
+// SimRouter picks the device and the SIM card for messages according to rules
+// on SIM metadata.
+//
+// SimRouter is safe for concurrent use if its rules are.
+type SimRouter struct {
+	rules []SimRule
+}
+
+// NewSimRouter creates a SimRouter applying the rules in the given order.
+// Without rules, the first SIM card of the first device is picked.
+func NewSimRouter(rules ...SimRule) *SimRouter {
+	return &SimRouter{rules: rules}
+}
+
+// Route returns a copy of the message with DeviceID and SimNumber set to one
+// of the SIM cards of the devices, e.g. from ListDevices or
+// DevicePool.Devices. A DeviceID or SimNumber already set in the message
+// restricts the candidates.
+//
+// It returns ErrValidationFailed if the SimNumber of the message is not a
+// valid slot of the device, and ErrNoDevices if no device reports SIM cards.
+func (r *SimRouter) Route(devices []Device, message Message) (Message, error) {
+	if message.SimNumber != nil && !isValidSimNumber(int(*message.SimNumber)) {
+		return message, fmt.Errorf(
+			"failed to route message: %w: simNumber must be between %d and %d",
+			ErrValidationFailed, minSimNumber, maxSimNumber,
+		)
+	}
+
+	candidates := simCandidates(devices, message)
+	if len(candidates) == 0 {
+		if message.SimNumber != nil {
+			return message, fmt.Errorf(
+				"failed to route message: %w: no device has a SIM card in slot %d",
+				ErrValidationFailed, *message.SimNumber,
+			)
+		}
+		return message, fmt.Errorf("failed to route message: %w", ErrNoDevices)
+	}
+
+	for _, rule := range r.rules {
+		if narrowed := rule(message, candidates); len(narrowed) > 0 {
+			candidates = narrowed
+		}
+	}
+
+	picked := candidates[0]
+	simNumber := uint8(picked.SimCard.SimNumber) //nolint:gosec // checked by simCandidates
+	message.DeviceID = picked.Device.ID
+	message.SimNumber = &simNumber
+
+	return message, nil
+}
+
+// ValidateSimNumber checks that the device has a SIM card in the slot. Devices
+// that don't report SIM cards are only checked for a valid slot number.
+func ValidateSimNumber(device Device, simNumber uint8) error {
+	if !isValidSimNumber(int(simNumber)) {
+		return fmt.Errorf("%w: simNumber must be between %d and %d", ErrValidationFailed, minSimNumber, maxSimNumber)
+	}
+	if len(device.SimCards) == 0 {
+		return nil
+	}
+
+	for _, sim := range device.SimCards {
+		if sim.SimNumber == int(simNumber) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: device %s has no SIM card in slot %d", ErrValidationFailed, device.ID, simNumber)
+}
+
+// simCandidates returns the SIM cards of the devices that match the DeviceID
+// and SimNumber of the message, if set.
+func simCandidates(devices []Device, message Message) []SimCandidate {
+	candidates := make([]SimCandidate, 0, len(devices))
+	for _, device := range devices {
+		if device.DeletedAt != nil || (message.DeviceID != "" && device.ID != message.DeviceID) {
+			continue
+		}
+
+		for _, sim := range device.SimCards {
+			if !isValidSimNumber(sim.SimNumber) {
+				continue
+			}
+			if message.SimNumber != nil && sim.SimNumber != int(*message.SimNumber) {
+				continue
+			}
+			candidates = append(candidates, SimCandidate{Device: device, SimCard: sim})
+		}
+	}
+	return candidates
+}
+
+func isValidSimNumber(simNumber int) bool {
+	return simNumber >= minSimNumber && simNumber <= maxSimNumber
+}
@@ -0,0 +1,165 @@
+package smsgateway_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+func simCard(simNumber int, carrier string) smsgateway.SimCard {
+	return smsgateway.SimCard{SlotIndex: simNumber - 1, SimNumber: simNumber, CarrierName: &carrier}
+}
+
+func uint8Ptr(v uint8) *uint8 {
+	return &v
+}
+
+func TestSimRouter_Route(t *testing.T) {
+	deleted := time.Now()
+	devices := []smsgateway.Device{
+		{ID: "a", SimCards: []smsgateway.SimCard{simCard(1, "MTS"), simCard(2, "Beeline")}},
+		{ID: "b", SimCards: []smsgateway.SimCard{simCard(1, "T-Mobile"), simCard(4, "Invalid")}},
+		{ID: "c", SimCards: []smsgateway.SimCard{simCard(1, "MTS")}, DeletedAt: &deleted},
+		{ID: "d"},
+	}
+	carriers := map[string]string{"+7": "mts", "+79": "beeline", "+1": "t-mobile"}
+
+	tests := []struct {
+		name       string
+		rules      []smsgateway.SimRule
+		message    smsgateway.Message
+		device     string
+		simNumber  uint8
+		expectErr  error
+		devicesArg []smsgateway.Device
+	}{
+		{
+			name:      "First SIM without rules",
+			message:   smsgateway.Message{PhoneNumbers: []string{"+79990001234"}},
+			device:    "a",
+			simNumber: 1,
+		},
+		{
+			name:      "Longest prefix",
+			rules:     []smsgateway.SimRule{smsgateway.RouteByPrefix(carriers)},
+			message:   smsgateway.Message{PhoneNumbers: []string{"+79990001234"}},
+			device:    "a",
+			simNumber: 2,
+		},
+		{
+			name:      "Prefix without plus",
+			rules:     []smsgateway.SimRule{smsgateway.RouteByPrefix(carriers)},
+			message:   smsgateway.Message{PhoneNumbers: []string{"12025550123"}},
+			device:    "b",
+			simNumber: 1,
+		},
+		{
+			name:      "Unknown prefix keeps candidates",
+			rules:     []smsgateway.SimRule{smsgateway.RouteByPrefix(carriers)},
+			message:   smsgateway.Message{PhoneNumbers: []string{"+442079460018"}},
+			device:    "a",
+			simNumber: 1,
+		},
+		{
+			name:      "Preferred carrier",
+			rules:     []smsgateway.SimRule{smsgateway.PreferCarrier("Vodafone", "T-Mobile")},
+			message:   smsgateway.Message{PhoneNumbers: []string{"+79990001234"}},
+			device:    "b",
+			simNumber: 1,
+		},
+		{
+			name:      "Explicit device",
+			message:   smsgateway.Message{DeviceID: "b", PhoneNumbers: []string{"+79990001234"}},
+			device:    "b",
+			simNumber: 1,
+		},
+		{
+			name:      "Explicit SIM number",
+			rules:     []smsgateway.SimRule{smsgateway.PreferCarrier("MTS")},
+			message:   smsgateway.Message{SimNumber: uint8Ptr(2), PhoneNumbers: []string{"+79990001234"}},
+			device:    "a",
+			simNumber: 2,
+		},
+		{
+			name:      "Invalid SIM number",
+			message:   smsgateway.Message{SimNumber: uint8Ptr(4), PhoneNumbers: []string{"+79990001234"}},
+			expectErr: smsgateway.ErrValidationFailed,
+		},
+		{
+			name:      "Missing SIM slot",
+			message:   smsgateway.Message{DeviceID: "b", SimNumber: uint8Ptr(2), PhoneNumbers: []string{"+79990001234"}},
+			expectErr: smsgateway.ErrValidationFailed,
+		},
+		{
+			name:       "No SIM cards",
+			message:    smsgateway.Message{PhoneNumbers: []string{"+79990001234"}},
+			expectErr:  smsgateway.ErrNoDevices,
+			devicesArg: devices[2:],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arg := devices
+			if tt.devicesArg != nil {
+				arg = tt.devicesArg
+			}
+
+			routed, err := smsgateway.NewSimRouter(tt.rules...).Route(arg, tt.message)
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("Route() error = %v, expected %v", err, tt.expectErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if routed.DeviceID != tt.device || routed.SimNumber == nil || *routed.SimNumber != tt.simNumber {
+				t.Errorf("Route() = %s/%v, expected %s/%d", routed.DeviceID, routed.SimNumber, tt.device, tt.simNumber)
+			}
+		})
+	}
+}
+
+func TestBalanceSlots(t *testing.T) {
+	devices := []smsgateway.Device{
+		{ID: "a", SimCards: []smsgateway.SimCard{simCard(1, "MTS"), simCard(2, "MTS")}},
+		{ID: "b", SimCards: []smsgateway.SimCard{simCard(1, "Beeline")}},
+	}
+	router := smsgateway.NewSimRouter(smsgateway.PreferCarrier("MTS"), smsgateway.BalanceSlots())
+
+	expected := []uint8{1, 2, 1, 2}
+	for i, simNumber := range expected {
+		routed, err := router.Route(devices, smsgateway.Message{PhoneNumbers: []string{"+79990001234"}})
+		if err != nil {
+			t.Fatalf("Route() error = %v", err)
+		}
+		if routed.DeviceID != "a" || *routed.SimNumber != simNumber {
+			t.Errorf("message %d routed to %s/%d, expected a/%d", i, routed.DeviceID, *routed.SimNumber, simNumber)
+		}
+	}
+}
+
+func TestValidateSimNumber(t *testing.T) {
+	tests := []struct {
+		name      string
+		device    smsgateway.Device
+		simNumber uint8
+		expectErr error
+	}{
+		{name: "Present", device: smsgateway.Device{SimCards: []smsgateway.SimCard{simCard(2, "MTS")}}, simNumber: 2},
+		{name: "Missing", device: smsgateway.Device{SimCards: []smsgateway.SimCard{simCard(2, "MTS")}}, simNumber: 1, expectErr: smsgateway.ErrValidationFailed},
+		{name: "Unknown SIM cards", device: smsgateway.Device{}, simNumber: 3},
+		{name: "Zero", device: smsgateway.Device{}, simNumber: 0, expectErr: smsgateway.ErrValidationFailed},
+		{name: "Out of range", device: smsgateway.Device{}, simNumber: 4, expectErr: smsgateway.ErrValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := smsgateway.ValidateSimNumber(tt.device, tt.simNumber); !errors.Is(err, tt.expectErr) {
+				t.Errorf("ValidateSimNumber() error = %v, expected %v", err, tt.expectErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package smsgateway
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SyncWebhooksOptions configures SyncWebhooks.
+type SyncWebhooksOptions struct {
+	// URLPrefix limits the sync to webhooks whose URL starts with the prefix.
+	// Other webhooks on the server are left untouched. Empty means all.
+	URLPrefix string
+	// DryRun computes the plan without changing anything on the server.
+	DryRun bool
+}
+
+// SyncWebhooksResult reports the changes made by SyncWebhooks, or planned in
+// dry-run mode.
+type SyncWebhooksResult struct {
+	Created   []Webhook // Webhooks registered, without server-assigned IDs in dry-run mode
+	Deleted   []Webhook // Webhooks deleted
+	Unchanged []Webhook // Webhooks already in the desired state
+}
+
+// Changed reports whether the sync created or deleted any webhooks.
+func (r SyncWebhooksResult) Changed() bool {
+	return len(r.Created) > 0 || len(r.Deleted) > 0
+}
+
+type webhookKey struct {
+	deviceID string
+	url      string
+	event    WebhookEvent
+}
+
+func keyOf(w Webhook) webhookKey {
+	key := webhookKey{deviceID: "", url: w.URL, event: w.Event}
+	if w.DeviceID != nil {
+		key.deviceID = *w.DeviceID
+	}
+	return key
+}
+
+// SyncWebhooks reconciles the webhooks on the server with the desired set.
+//
+// Webhooks are matched by device ID, URL and event. Missing webhooks are
+// registered and extra ones, including duplicates, are deleted. IDs of desired
+// webhooks are ignored. On error, the result reports the changes made so far.
+//
+// Requires the ScopeWebhooksList, ScopeWebhooksWrite and ScopeWebhooksDelete
+// scopes.
+func (c *Client) SyncWebhooks(
+	ctx context.Context,
+	desired []Webhook,
+	opts SyncWebhooksOptions,
+) (SyncWebhooksResult, error) {
+	result := SyncWebhooksResult{Created: nil, Deleted: nil, Unchanged: nil}
+
+	wanted := make(map[webhookKey]Webhook, len(desired))
+	order := make([]webhookKey, 0, len(desired))
+	for _, webhook := range desired {
+		if !strings.HasPrefix(webhook.URL, opts.URLPrefix) {
+			return result, fmt.Errorf(
+				"%w: webhook url %q is outside of prefix %q",
+				ErrValidationFailed, webhook.URL, opts.URLPrefix,
+			)
+		}
+
+		key := keyOf(webhook)
+		if _, ok := wanted[key]; !ok {
+			order = append(order, key)
+		}
+		webhook.ID = ""
+		wanted[key] = webhook
+	}
+
+	current, err := c.ListWebhooks(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	existing := make(map[webhookKey]struct{}, len(current))
+	var extra []Webhook
+	for _, webhook := range current {
+		if !strings.HasPrefix(webhook.URL, opts.URLPrefix) {
+			continue
+		}
+
+		key := keyOf(webhook)
+		_, isWanted := wanted[key]
+		_, isDuplicate := existing[key]
+		if !isWanted || isDuplicate {
+			extra = append(extra, webhook)
+			continue
+		}
+
+		existing[key] = struct{}{}
+		result.Unchanged = append(result.Unchanged, webhook)
+	}
+
+	// Create before deleting, so events are not lost while webhooks move.
+	for _, key := range order {
+		if _, ok := existing[key]; ok {
+			continue
+		}
+
+		webhook := wanted[key]
+		if !opts.DryRun {
+			if webhook, err = c.RegisterWebhook(ctx, webhook); err != nil {
+				return result, err
+			}
+		}
+		result.Created = append(result.Created, webhook)
+	}
+
+	for _, webhook := range extra {
+		if !opts.DryRun {
+			if err = c.DeleteWebhook(ctx, webhook.ID); err != nil {
+				return result, err
+			}
+		}
+		result.Deleted = append(result.Deleted, webhook)
+	}
+
+	return result, nil
+}
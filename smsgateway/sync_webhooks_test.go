@@ -0,0 +1,165 @@
+package smsgateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+// webhooksServer keeps webhooks in memory and counts the changes.
+type webhooksServer struct {
+	webhooks []smsgateway.Webhook
+	nextID   int
+	writes   int
+}
+
+func newWebhooksServer(webhooks ...smsgateway.Webhook) *webhooksServer {
+	return &webhooksServer{webhooks: webhooks, nextID: len(webhooks), writes: 0}
+}
+
+func (s *webhooksServer) handle(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/webhooks":
+		_ = json.NewEncoder(w).Encode(s.webhooks)
+	case r.Method == http.MethodPost && r.URL.Path == "/webhooks":
+		webhook := smsgateway.Webhook{}
+		_ = json.NewDecoder(r.Body).Decode(&webhook)
+		s.nextID++
+		webhook.ID = fmt.Sprintf("wh%d", s.nextID)
+		s.webhooks = append(s.webhooks, webhook)
+		s.writes++
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(webhook)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/webhooks/"):
+		id := strings.TrimPrefix(r.URL.Path, "/webhooks/")
+		for i, webhook := range s.webhooks {
+			if webhook.ID == id {
+				s.webhooks = append(s.webhooks[:i], s.webhooks[i+1:]...)
+				break
+			}
+		}
+		s.writes++
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func webhookIDs(webhooks []smsgateway.Webhook) []string {
+	ids := make([]string, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		ids = append(ids, webhook.ID)
+	}
+	return ids
+}
+
+func TestClient_SyncWebhooks(t *testing.T) {
+	device := "dev1"
+	initial := []smsgateway.Webhook{
+		{ID: "wh1", URL: "https://svc.example.com/sms", Event: smsgateway.WebhookEventSmsReceived},
+		{ID: "wh2", URL: "https://svc.example.com/sms", Event: smsgateway.WebhookEventSmsFailed},
+		{ID: "wh3", URL: "https://svc.example.com/sms", Event: smsgateway.WebhookEventSmsReceived},
+		{ID: "wh4", URL: "https://other.example.com/hook", Event: smsgateway.WebhookEventSmsReceived},
+	}
+	desired := []smsgateway.Webhook{
+		{URL: "https://svc.example.com/sms", Event: smsgateway.WebhookEventSmsReceived},
+		{URL: "https://svc.example.com/sms", Event: smsgateway.WebhookEventSmsDelivered},
+		{DeviceID: &device, URL: "https://svc.example.com/sms", Event: smsgateway.WebhookEventSmsReceived},
+	}
+
+	tests := []struct {
+		name      string
+		opts      smsgateway.SyncWebhooksOptions
+		created   int
+		deleted   []string
+		unchanged []string
+		remaining int
+	}{
+		{
+			name:      "All webhooks",
+			opts:      smsgateway.SyncWebhooksOptions{},
+			created:   2,
+			deleted:   []string{"wh2", "wh3", "wh4"},
+			unchanged: []string{"wh1"},
+			remaining: 3,
+		},
+		{
+			name:      "URL prefix",
+			opts:      smsgateway.SyncWebhooksOptions{URLPrefix: "https://svc.example.com/"},
+			created:   2,
+			deleted:   []string{"wh2", "wh3"},
+			unchanged: []string{"wh1"},
+			remaining: 4,
+		},
+		{
+			name:      "Dry run",
+			opts:      smsgateway.SyncWebhooksOptions{URLPrefix: "https://svc.example.com/", DryRun: true},
+			created:   2,
+			deleted:   []string{"wh2", "wh3"},
+			unchanged: []string{"wh1"},
+			remaining: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newWebhooksServer(append([]smsgateway.Webhook(nil), initial...)...)
+			client := newTestClient(t, serialized(server.handle))
+
+			result, err := client.SyncWebhooks(context.Background(), desired, tt.opts)
+			if err != nil {
+				t.Fatalf("SyncWebhooks() unexpected error = %v", err)
+			}
+
+			if len(result.Created) != tt.created {
+				t.Errorf("expected %d created, got %+v", tt.created, result.Created)
+			}
+			if got := strings.Join(webhookIDs(result.Deleted), ","); got != strings.Join(tt.deleted, ",") {
+				t.Errorf("expected deleted %v, got %s", tt.deleted, got)
+			}
+			if got := strings.Join(webhookIDs(result.Unchanged), ","); got != strings.Join(tt.unchanged, ",") {
+				t.Errorf("expected unchanged %v, got %s", tt.unchanged, got)
+			}
+			if !result.Changed() {
+				t.Error("expected result to report changes")
+			}
+
+			if tt.opts.DryRun {
+				if server.writes != 0 {
+					t.Errorf("dry run must not change the server, got %d writes", server.writes)
+				}
+				return
+			}
+			if len(server.webhooks) != tt.remaining {
+				t.Errorf("expected %d webhooks on server, got %d", tt.remaining, len(server.webhooks))
+			}
+
+			// A second sync is a no-op.
+			again, err := client.SyncWebhooks(context.Background(), desired, tt.opts)
+			if err != nil {
+				t.Fatalf("SyncWebhooks() unexpected error = %v", err)
+			}
+			if again.Changed() {
+				t.Errorf("expected second sync to be a no-op, got %+v", again)
+			}
+		})
+	}
+}
+
+func TestClient_SyncWebhooks_OutsidePrefix(t *testing.T) {
+	server := newWebhooksServer()
+	client := newTestClient(t, serialized(server.handle))
+
+	_, err := client.SyncWebhooks(context.Background(), []smsgateway.Webhook{
+		{URL: "https://other.example.com/hook", Event: smsgateway.WebhookEventSmsReceived},
+	}, smsgateway.SyncWebhooksOptions{URLPrefix: "https://svc.example.com/"})
+	if !errors.Is(err, smsgateway.ErrValidationFailed) {
+		t.Errorf("expected ErrValidationFailed, got %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package smsgateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultTokenRefreshBefore is how long before expiration a token is refreshed.
+const DefaultTokenRefreshBefore = time.Minute
+
+const authorizationHeader = "Authorization"
+
+// TokenSource provides access tokens for Bearer authentication.
+//
+// Implementations must be safe for concurrent use.
+type TokenSource interface {
+	// Token returns a valid access token.
+	Token(ctx context.Context) (string, error)
+}
+
+// tokenInvalidator is implemented by token sources that can discard a token
+// rejected by the server, so the next call to Token obtains a new one.
+type tokenInvalidator interface {
+	Invalidate(token string)
+}
+
+// TokenManagerOption configures a TokenManager.
+type TokenManagerOption func(*TokenManager)
+
+// WithTokenRefreshBefore sets how long before expiration the access token is
+// refreshed. Defaults to DefaultTokenRefreshBefore.
+func WithTokenRefreshBefore(d time.Duration) TokenManagerOption {
+	return func(m *TokenManager) {
+		m.refreshBefore = d
+	}
+}
+
+// TokenManager is a TokenSource and Authenticator that manages the access token lifecycle.
+//
+// It bootstraps a JWT with GenerateToken using Basic credentials and the
+// requested scopes, refreshes it with the refresh token shortly before it
+// expires, and falls back to generating a new token if the refresh fails.
+//
+// TokenManager is safe for concurrent use.
+type TokenManager struct {
+	client         *Client
+	hasCredentials bool
+	request        TokenRequest
+	refreshBefore  time.Duration
+	now            func() time.Time
+
+	mu        sync.Mutex
+	token     *TokenResponse
+	refreshAt time.Time
+}
+
+// NewTokenManager creates a TokenManager that obtains tokens using the Basic
+// credentials from the config.
+func NewTokenManager(config Config, request TokenRequest, options ...TokenManagerOption) *TokenManager {
+	config.Token = ""
+	config.TokenSource = nil
+	config.Authenticator = nil
+
+	m := &TokenManager{
+		client:         NewClient(config),
+		hasCredentials: config.User != "" && config.Password != "",
+		request:        request,
+		refreshBefore:  DefaultTokenRefreshBefore,
+		now:            time.Now,
+
+		mu:        sync.Mutex{},
+		token:     nil,
+		refreshAt: time.Time{},
+	}
+
+	for _, option := range options {
+		option(m)
+	}
+
+	return m
+}
+
+// Token returns a valid access token, obtaining or refreshing it if needed.
+func (m *TokenManager) Token(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.token != nil && m.now().Before(m.refreshAt) {
+		return m.token.AccessToken, nil
+	}
+
+	if m.token != nil && m.token.RefreshToken != "" {
+		if resp, err := m.client.RefreshToken(ctx, m.token.RefreshToken); err == nil {
+			m.store(resp)
+			return resp.AccessToken, nil
+		}
+	}
+
+	resp, err := m.client.GenerateToken(ctx, m.request)
+	if err != nil {
+		return "", err
+	}
+
+	m.store(resp)
+	return resp.AccessToken, nil
+}
+
+// Apply sets the Bearer `Authorization` header with a valid access token, so
+// TokenManager can be used as an Authenticator.
+func (m *TokenManager) Apply(ctx context.Context, req *http.Request) error {
+	return applyTokenSource(ctx, m, req)
+}
+
+// Validate checks that scopes and Basic credentials are configured.
+func (m *TokenManager) Validate() error {
+	if len(m.request.Scopes) == 0 {
+		return fmt.Errorf("%w: missing token scopes", ErrInvalidConfig)
+	}
+	if !m.hasCredentials {
+		return fmt.Errorf("%w: missing basic auth credentials", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// Invalidate discards the given access token if it is still the current one,
+// so the next call to Token refreshes it.
+func (m *TokenManager) Invalidate(token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.token != nil && m.token.AccessToken == token {
+		m.refreshAt = time.Time{}
+	}
+}
+
+// Revoke revokes the current access token, if any.
+func (m *TokenManager) Revoke(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.token == nil {
+		return nil
+	}
+
+	if err := m.client.RevokeToken(ctx, m.token.ID); err != nil {
+		return err
+	}
+
+	m.token = nil
+	m.refreshAt = time.Time{}
+	return nil
+}
+
+func (m *TokenManager) store(resp TokenResponse) {
+	now := m.now()
+	refreshBefore := m.refreshBefore
+	if lifetime := resp.ExpiresAt.Sub(now); lifetime/2 < refreshBefore {
+		refreshBefore = lifetime / 2
+	}
+
+	m.token = &resp
+	m.refreshAt = resp.ExpiresAt.Add(-refreshBefore)
+}
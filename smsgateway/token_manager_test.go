@@ -0,0 +1,237 @@
+package smsgateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/rest"
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+// tokenServer issues, refreshes and revokes tokens and accepts requests with
+// valid access tokens.
+type tokenServer struct {
+	ttl       time.Duration
+	generated int
+	refreshed int
+	revoked   int
+	valid     map[string]bool
+	refresh   map[string]bool
+}
+
+func newTokenServer(ttl time.Duration) *tokenServer {
+	return &tokenServer{
+		ttl:     ttl,
+		valid:   map[string]bool{},
+		refresh: map[string]bool{},
+	}
+}
+
+func (s *tokenServer) issue(w http.ResponseWriter) {
+	n := s.generated + s.refreshed
+	access := fmt.Sprintf("access-%d", n)
+	refresh := fmt.Sprintf("refresh-%d", n)
+	s.valid[access] = true
+	s.refresh[refresh] = true
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(smsgateway.TokenResponse{
+		ID:           fmt.Sprintf("jti-%d", n),
+		TokenType:    "Bearer",
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresAt:    time.Now().Add(s.ttl),
+	})
+}
+
+func (s *tokenServer) handle(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+
+	switch {
+	case r.URL.Path == "/auth/token" && r.Method == http.MethodPost:
+		if auth != authorizationHeader {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		s.generated++
+		s.issue(w)
+	case r.URL.Path == "/auth/token/refresh":
+		token := auth[len("Bearer "):]
+		ok := s.refresh[token]
+		delete(s.refresh, token)
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		s.refreshed++
+		s.issue(w)
+	case r.URL.Path == "/auth/token/jti-1" && r.Method == http.MethodDelete:
+		s.revoked++
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		if len(auth) <= len("Bearer ") || !s.valid[auth[len("Bearer "):]] {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id":"123","state":"Pending"}`))
+	}
+}
+
+func newTokenManagedClient(
+	t *testing.T,
+	server *tokenServer,
+	pass string,
+) (*smsgateway.Client, *smsgateway.TokenManager) {
+	t.Helper()
+
+	config := testConfig(newTestServer(t, serialized(server.handle)).URL)
+	config.Password = pass
+	manager := smsgateway.NewTokenManager(config, smsgateway.TokenRequest{
+		Scopes: []smsgateway.JWTScope{smsgateway.ScopeMessagesRead},
+	})
+
+	return smsgateway.NewClient(config.WithTokenSource(manager)), manager
+}
+
+func TestTokenManager_Bootstrap(t *testing.T) {
+	server := newTokenServer(time.Hour)
+	client, _ := newTokenManagedClient(t, server, password)
+
+	for range 3 {
+		if _, err := client.GetState(context.Background(), "123"); err != nil {
+			t.Fatalf("GetState() unexpected error = %v", err)
+		}
+	}
+
+	if got := server.generated; got != 1 {
+		t.Errorf("expected 1 generated token, got %d", got)
+	}
+	if got := server.refreshed; got != 0 {
+		t.Errorf("expected no refreshes, got %d", got)
+	}
+}
+
+func TestTokenManager_RefreshBeforeExpiration(t *testing.T) {
+	server := newTokenServer(time.Second)
+	client, _ := newTokenManagedClient(t, server, password)
+
+	if _, err := client.GetState(context.Background(), "123"); err != nil {
+		t.Fatalf("GetState() unexpected error = %v", err)
+	}
+
+	time.Sleep(750 * time.Millisecond)
+
+	if _, err := client.GetState(context.Background(), "123"); err != nil {
+		t.Fatalf("GetState() unexpected error = %v", err)
+	}
+
+	if got := server.generated; got != 1 {
+		t.Errorf("expected 1 generated token, got %d", got)
+	}
+	if got := server.refreshed; got != 1 {
+		t.Errorf("expected 1 refresh, got %d", got)
+	}
+}
+
+func TestTokenManager_RetryOnUnauthorized(t *testing.T) {
+	server := newTokenServer(time.Hour)
+	client, manager := newTokenManagedClient(t, server, password)
+
+	token, err := manager.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() unexpected error = %v", err)
+	}
+	delete(server.valid, token)
+
+	if _, err := client.GetState(context.Background(), "123"); err != nil {
+		t.Fatalf("GetState() unexpected error = %v", err)
+	}
+
+	if got := server.refreshed; got != 1 {
+		t.Errorf("expected 1 refresh, got %d", got)
+	}
+}
+
+func TestTokenManager_InvalidCredentials(t *testing.T) {
+	server := newTokenServer(time.Hour)
+
+	client, _ := newTokenManagedClient(t, server, "wrong")
+
+	_, err := client.GetState(context.Background(), "123")
+	if !rest.IsUnauthorized(err) {
+		t.Errorf("expected unauthorized error, got %v", err)
+	}
+}
+
+func TestTokenManager_Concurrent(t *testing.T) {
+	server := newTokenServer(time.Hour)
+	client, _ := newTokenManagedClient(t, server, password)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetState(context.Background(), "123"); err != nil {
+				t.Errorf("GetState() unexpected error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := server.generated; got != 1 {
+		t.Errorf("expected 1 generated token, got %d", got)
+	}
+}
+
+func TestTokenManager_Revoke(t *testing.T) {
+	server := newTokenServer(time.Hour)
+	_, manager := newTokenManagedClient(t, server, password)
+
+	if err := manager.Revoke(context.Background()); err != nil {
+		t.Fatalf("Revoke() without token unexpected error = %v", err)
+	}
+	if _, err := manager.Token(context.Background()); err != nil {
+		t.Fatalf("Token() unexpected error = %v", err)
+	}
+	if err := manager.Revoke(context.Background()); err != nil {
+		t.Fatalf("Revoke() unexpected error = %v", err)
+	}
+	if got := server.revoked; got != 1 {
+		t.Errorf("expected 1 revoked token, got %d", got)
+	}
+}
+
+func TestClient_RefreshTokenWithTokenSource(t *testing.T) {
+	server := newMockServer(mockServerExpectedInput{
+		method:        http.MethodPost,
+		path:          "/auth/token/refresh",
+		authorization: "Bearer refresh",
+	}, mockServerOutput{
+		code: http.StatusOK,
+		body: `{"access_token":"new"}`,
+	})
+	defer server.Close()
+
+	client := smsgateway.NewClient(smsgateway.Config{BaseURL: server.URL}.WithTokenSource(staticTokenSource("x")))
+
+	resp, err := client.RefreshToken(context.Background(), "refresh")
+	if err != nil {
+		t.Fatalf("RefreshToken() unexpected error = %v", err)
+	}
+	if resp.AccessToken != "new" {
+		t.Errorf("expected access token %q, got %q", "new", resp.AccessToken)
+	}
+}
+
+type staticTokenSource string
+
+func (s staticTokenSource) Token(context.Context) (string, error) {
+	return string(s), nil
+}
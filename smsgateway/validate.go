@@ -0,0 +1,325 @@
+package smsgateway
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const sha256HexLength = 64
+
+// ValidationError describes a field that failed a `validate` tag rule.
+type ValidationError struct {
+	Field  string // Path of the field using JSON names, e.g. `phoneNumbers[0]`
+	Tag    string // Failed rule, e.g. `max`
+	Param  string // Rule parameter, e.g. `128`
+	Reason string // Human-readable description of the failure
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrValidationFailed, e.Field, e.Reason)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidationFailed
+}
+
+// ValidationErrors is a list of field validation errors.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	reasons := make([]string, 0, len(e))
+	for _, err := range e {
+		reasons = append(reasons, err.Field+": "+err.Reason)
+	}
+	return fmt.Sprintf("%s: %s", ErrValidationFailed, strings.Join(reasons, "; "))
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// ValidateStruct checks the struct against its `validate` tags.
+//
+// The following rules are supported: required, omitempty, isdefault, min, max,
+// oneof, dive, base64, sha256, url, http_url, ltefield and gtefield. Other
+// rules are ignored. Nested structs are validated recursively.
+//
+// It returns ValidationErrors listing every failed field, which wraps
+// ErrValidationFailed.
+func ValidateStruct(v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: expected struct, got %T", ErrValidationFailed, v)
+	}
+
+	var errs ValidationErrors
+	validateStruct(rv, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+type validationRule struct {
+	tag   string
+	param string
+}
+
+func parseRules(tag string) []validationRule {
+	if tag == "" {
+		return nil
+	}
+
+	parts := strings.Split(tag, ",")
+	rules := make([]validationRule, 0, len(parts))
+	for _, part := range parts {
+		name, param, _ := strings.Cut(part, "=")
+		rules = append(rules, validationRule{tag: name, param: param})
+	}
+	return rules
+}
+
+func validateStruct(rv reflect.Value, prefix string, errs *ValidationErrors) {
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		path := prefix
+		if !field.Anonymous || name != "" {
+			if name == "" {
+				name = field.Name
+			}
+			path = joinPath(prefix, name)
+		}
+
+		validateValue(rv, rv.Field(i), path, parseRules(field.Tag.Get("validate")), errs)
+	}
+}
+
+func validateValue(parent, v reflect.Value, path string, rules []validationRule, errs *ValidationErrors) {
+	for i, rule := range rules {
+		switch rule.tag {
+		case "omitempty":
+			if isEmpty(v) {
+				return
+			}
+			continue
+		case "required":
+			if isEmpty(v) {
+				errs.add(path, rule, "is required")
+				return
+			}
+			continue
+		case "dive":
+			validateElements(v, path, rules[i+1:], errs)
+			return
+		}
+
+		if reason, ok := checkRule(parent, indirect(v), rule); !ok {
+			errs.add(path, rule, reason)
+			return
+		}
+	}
+
+	if elem := indirect(v); elem.Kind() == reflect.Struct && elem.Type() != reflect.TypeOf(time.Time{}) {
+		validateStruct(elem, path, errs)
+	}
+}
+
+func validateElements(v reflect.Value, path string, rules []validationRule, errs *ValidationErrors) {
+	v = indirect(v)
+
+	switch v.Kind() { //nolint:exhaustive // only containers can be dived into
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			validateValue(v, v.Index(i), fmt.Sprintf("%s[%d]", path, i), rules, errs)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			validateValue(v, iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), rules, errs)
+		}
+	}
+}
+
+// checkRule checks the value against the rule. Unknown rules and nil values
+// always pass.
+func checkRule(parent, v reflect.Value, rule validationRule) (string, bool) {
+	if !v.IsValid() {
+		return "", true
+	}
+
+	switch rule.tag {
+	case "isdefault":
+		if !v.IsZero() {
+			return "must not be set", false
+		}
+	case "min":
+		return checkBound(v, rule.param, false)
+	case "max":
+		return checkBound(v, rule.param, true)
+	case "oneof":
+		value := fmt.Sprint(v.Interface())
+		for _, option := range strings.Fields(rule.param) {
+			if value == option {
+				return "", true
+			}
+		}
+		return fmt.Sprintf("must be one of [%s]", rule.param), false
+	case "base64":
+		if _, err := base64.StdEncoding.DecodeString(v.String()); err != nil || v.String() == "" {
+			return "must be a valid base64 string", false
+		}
+	case "sha256":
+		if _, err := hex.DecodeString(v.String()); err != nil || len(v.String()) != sha256HexLength {
+			return "must be a SHA256 hex digest", false
+		}
+	case "url":
+		if u, err := url.Parse(v.String()); err != nil || u.Scheme == "" {
+			return "must be a valid URL", false
+		}
+	case "http_url":
+		if u, err := url.Parse(v.String()); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "must be a valid HTTP(S) URL", false
+		}
+	case "ltefield":
+		return checkField(parent, v, rule.param, true)
+	case "gtefield":
+		return checkField(parent, v, rule.param, false)
+	}
+
+	return "", true
+}
+
+func checkBound(v reflect.Value, param string, isMax bool) (string, bool) {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return "", true
+	}
+
+	var (
+		value float64
+		unit  string
+	)
+	switch v.Kind() { //nolint:exhaustive // other kinds have no bounds
+	case reflect.String:
+		value, unit = float64(utf8.RuneCountInString(v.String())), " characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		value, unit = float64(v.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = v.Float()
+	default:
+		return "", true
+	}
+
+	switch {
+	case isMax && value > limit:
+		return fmt.Sprintf("must be at most %s%s", param, unit), false
+	case !isMax && value < limit:
+		return fmt.Sprintf("must be at least %s%s", param, unit), false
+	}
+	return "", true
+}
+
+func checkField(parent, v reflect.Value, name string, isLTE bool) (string, bool) {
+	if parent.Kind() != reflect.Struct {
+		return "", true
+	}
+
+	other := indirect(parent.FieldByName(name))
+	if !other.IsValid() || other.Type() != v.Type() {
+		return "", true
+	}
+
+	var cmp int
+	switch {
+	case v.Type() == reflect.TypeOf(time.Time{}):
+		cmp = v.Interface().(time.Time).Compare(other.Interface().(time.Time)) //nolint:forcetypeassert // checked above
+	case v.CanInt():
+		cmp = compare(v.Int(), other.Int())
+	case v.CanUint():
+		cmp = compare(v.Uint(), other.Uint())
+	case v.CanFloat():
+		cmp = compare(v.Float(), other.Float())
+	default:
+		return "", true
+	}
+
+	switch {
+	case isLTE && cmp > 0:
+		return "must be less than or equal to " + name, false
+	case !isLTE && cmp < 0:
+		return "must be greater than or equal to " + name, false
+	}
+	return "", true
+}
+
+func compare[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (e *ValidationErrors) add(path string, rule validationRule, reason string) {
+	*e = append(*e, &ValidationError{Field: path, Tag: rule.tag, Param: rule.param, Reason: reason})
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() { //nolint:exhaustive // other kinds are compared with zero value
+	case reflect.Invalid:
+		return true
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
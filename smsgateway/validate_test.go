@@ -0,0 +1,306 @@
+package smsgateway_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+func TestValidateStruct(t *testing.T) {
+	since := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	simNumber := uint8(4)
+	ttl := uint64(1)
+	limitPeriod := smsgateway.LimitPeriod("Weekly")
+	secret, empty := "secret", ""
+
+	tests := []struct {
+		name     string
+		value    any
+		expected []string // failed field paths
+	}{
+		{
+			name: "Valid message",
+			value: smsgateway.Message{
+				TextMessage:  &smsgateway.TextMessage{Text: "Hello"},
+				PhoneNumbers: []string{"+79990001234"},
+			},
+			expected: nil,
+		},
+		{
+			name: "Message without recipients",
+			value: &smsgateway.Message{
+				TextMessage: &smsgateway.TextMessage{Text: "Hello"},
+			},
+			expected: []string{"phoneNumbers"},
+		},
+		{
+			name: "Message with invalid fields",
+			value: smsgateway.Message{
+				ID:           strings.Repeat("a", 37),
+				TextMessage:  &smsgateway.TextMessage{Text: ""},
+				DataMessage:  &smsgateway.DataMessage{Data: "not base64!", Port: 0},
+				PhoneNumbers: []string{"+79990001234", "", strings.Repeat("1", 129)},
+				SimNumber:    &simNumber,
+				TTL:          &ttl,
+			},
+			expected: []string{
+				"id",
+				"textMessage.text",
+				"dataMessage.data",
+				"dataMessage.port",
+				"phoneNumbers[1]",
+				"phoneNumbers[2]",
+				"simNumber",
+				"ttl",
+			},
+		},
+		{
+			name: "Too many recipients",
+			value: smsgateway.Message{
+				TextMessage:  &smsgateway.TextMessage{Text: "Hello"},
+				PhoneNumbers: make([]string, 101),
+			},
+			expected: []string{"phoneNumbers"},
+		},
+		{
+			name:     "Webhook with invalid URL",
+			value:    smsgateway.Webhook{URL: "ftp://example.com", Event: smsgateway.WebhookEventSmsReceived},
+			expected: []string{"url"},
+		},
+		{
+			name:     "Valid webhook",
+			value:    smsgateway.Webhook{URL: "https://example.com", Event: smsgateway.WebhookEventSmsReceived},
+			expected: nil,
+		},
+		{
+			name:     "Token request without scopes",
+			value:    smsgateway.TokenRequest{Scopes: []string{}},
+			expected: []string{"scopes"},
+		},
+		{
+			name:     "Token request with empty scope",
+			value:    smsgateway.TokenRequest{Scopes: []string{""}},
+			expected: []string{"scopes[0]"},
+		},
+		{
+			name: "Inbox refresh with inverted range",
+			value: smsgateway.InboxRefreshRequest{
+				Since:        since,
+				Until:        since.Add(-time.Hour),
+				MessageTypes: []smsgateway.IncomingMessageType{"SMS", "RCS"},
+			},
+			expected: []string{"since", "until", "messageTypes[1]"},
+		},
+		{
+			name: "Nested settings",
+			value: smsgateway.DeviceSettings{
+				Messages: &smsgateway.SettingsMessages{LimitPeriod: &limitPeriod},
+			},
+			expected: []string{"messages.limit_period"},
+		},
+		{
+			name: "Settings not allowed with Cloud Server",
+			value: smsgateway.DeviceSettings{
+				Encryption: &smsgateway.SettingsEncryption{Passphrase: &secret},
+				Webhooks:   &smsgateway.SettingsWebhooks{SigningKey: &secret},
+				Gateway:    &smsgateway.SettingsGateway{CloudURL: &secret, PrivateToken: &secret},
+			},
+			expected: []string{"encryption.passphrase", "webhooks.signing_key", "gateway.cloud_url", "gateway.private_token"},
+		},
+		{
+			name: "Settings with default values",
+			value: smsgateway.DeviceSettings{
+				Encryption: &smsgateway.SettingsEncryption{Passphrase: &empty},
+				Gateway:    &smsgateway.SettingsGateway{CloudURL: nil, PrivateToken: &empty},
+			},
+			expected: nil,
+		},
+		{
+			name: "Embedded struct",
+			value: smsgateway.HashedMessage{
+				Hash: strings.Repeat("a", 64),
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := smsgateway.ValidateStruct(tt.value)
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Errorf("ValidateStruct() unexpected error = %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, smsgateway.ErrValidationFailed) {
+				t.Fatalf("expected ErrValidationFailed, got %v", err)
+			}
+
+			var validationErrs smsgateway.ValidationErrors
+			if !errors.As(err, &validationErrs) {
+				t.Fatalf("expected ValidationErrors, got %T", err)
+			}
+
+			fields := make([]string, 0, len(validationErrs))
+			for _, e := range validationErrs {
+				fields = append(fields, e.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected failed fields %v, got %v (%v)", tt.expected, fields, err)
+			}
+		})
+	}
+}
+
+func TestValidateStruct_Error(t *testing.T) {
+	err := smsgateway.ValidateStruct(smsgateway.Webhook{URL: "https://example.com", Event: ""})
+
+	var fieldErr *smsgateway.ValidationError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("expected ValidationError, got %T", err)
+	}
+	if fieldErr.Field != "event" || fieldErr.Tag != "required" {
+		t.Errorf("unexpected error details: %+v", fieldErr)
+	}
+	if got := err.Error(); got != "validation failed: event: is required" {
+		t.Errorf("unexpected error message: %s", got)
+	}
+
+	if err = smsgateway.ValidateStruct("not a struct"); !errors.Is(err, smsgateway.ErrValidationFailed) {
+		t.Errorf("expected ErrValidationFailed for non-struct, got %v", err)
+	}
+}
+
+func TestClient_StrictValidation(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	strict := smsgateway.NewClient(
+		smsgateway.Config{BaseURL: server.URL, User: username, Password: password}.WithStrictValidation(),
+	)
+	ctx := context.Background()
+	interval := 1
+
+	tests := []struct {
+		name string
+		call func(*smsgateway.Client) error
+	}{
+		{
+			name: "Send without content",
+			call: func(c *smsgateway.Client) error {
+				_, err := c.Send(ctx, smsgateway.Message{PhoneNumbers: []string{"+79990001234"}})
+				return err
+			},
+		},
+		{
+			name: "Send without recipients",
+			call: func(c *smsgateway.Client) error {
+				_, err := c.Send(ctx, smsgateway.Message{TextMessage: &smsgateway.TextMessage{Text: "Hi"}})
+				return err
+			},
+		},
+		{
+			name: "RegisterWebhook with invalid event",
+			call: func(c *smsgateway.Client) error {
+				_, err := c.RegisterWebhook(ctx, smsgateway.Webhook{URL: "https://example.com", Event: "sms:unknown"})
+				return err
+			},
+		},
+		{
+			name: "UpdateSettings with inverted intervals",
+			call: func(c *smsgateway.Client) error {
+				minInterval, maxInterval := 10, 1
+				_, err := c.UpdateSettings(ctx, smsgateway.DeviceSettings{
+					Messages: &smsgateway.SettingsMessages{SendIntervalMin: &minInterval, SendIntervalMax: &maxInterval},
+				})
+				return err
+			},
+		},
+		{
+			name: "ReplaceSettings with invalid interval",
+			call: func(c *smsgateway.Client) error {
+				invalid := 0
+				_, err := c.ReplaceSettings(ctx, smsgateway.DeviceSettings{
+					Ping: &smsgateway.SettingsPing{IntervalSeconds: &invalid},
+				})
+				return err
+			},
+		},
+		{
+			name: "UpdateSettings with passphrase",
+			call: func(c *smsgateway.Client) error {
+				passphrase := "secret"
+				_, err := c.UpdateSettings(ctx, smsgateway.DeviceSettings{
+					Encryption: &smsgateway.SettingsEncryption{Passphrase: &passphrase},
+				})
+				return err
+			},
+		},
+		{
+			name: "ReplaceSettings with private token",
+			call: func(c *smsgateway.Client) error {
+				token := "secret"
+				_, err := c.ReplaceSettings(ctx, smsgateway.DeviceSettings{
+					Gateway: &smsgateway.SettingsGateway{PrivateToken: &token},
+				})
+				return err
+			},
+		},
+		{
+			name: "GenerateToken without scopes",
+			call: func(c *smsgateway.Client) error {
+				_, err := c.GenerateToken(ctx, smsgateway.TokenRequest{})
+				return err
+			},
+		},
+		{
+			name: "RefreshInbox without range",
+			call: func(c *smsgateway.Client) error {
+				return c.RefreshInbox(ctx, smsgateway.InboxRefreshRequest{})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = 0
+			if err := tt.call(strict); !errors.Is(err, smsgateway.ErrValidationFailed) {
+				t.Errorf("expected ErrValidationFailed, got %v", err)
+			}
+			if requests != 0 {
+				t.Errorf("expected no requests, got %d", requests)
+			}
+		})
+	}
+
+	t.Run("Valid request", func(t *testing.T) {
+		requests = 0
+		_, err := strict.UpdateSettings(ctx, smsgateway.DeviceSettings{
+			Ping: &smsgateway.SettingsPing{IntervalSeconds: &interval},
+		})
+		if err != nil || requests != 1 {
+			t.Errorf("expected valid request to be sent, got %d requests, %v", requests, err)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		requests = 0
+		lenient := newClient(server.URL)
+		if _, err := lenient.GenerateToken(ctx, smsgateway.TokenRequest{}); err != nil || requests != 1 {
+			t.Errorf("expected request to be sent without validation, got %d requests, %v", requests, err)
+		}
+	})
+}
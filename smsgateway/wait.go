@@ -0,0 +1,169 @@
+package smsgateway
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/rest"
+)
+
+const (
+	DefaultWaitPollInterval    = time.Second      // Default delay between state polls
+	DefaultWaitMaxPollInterval = 30 * time.Second // Default upper bound of the delay between state polls
+	DefaultWaitBackoff         = 1.5              // Default multiplier applied to the delay after each poll
+)
+
+// WaitOptions configures WaitForState.
+type WaitOptions struct {
+	PollInterval    time.Duration     // Delay before the second poll, defaults to DefaultWaitPollInterval
+	MaxPollInterval time.Duration     // Upper bound of the delay, defaults to DefaultWaitMaxPollInterval
+	Backoff         float64           // Delay multiplier after each poll, defaults to DefaultWaitBackoff; 1 polls at a fixed interval
+	States          []ProcessingState // Target states, defaults to Delivered and Failed
+	PerRecipient    bool              // Wait until every recipient reaches a target state instead of the message itself
+
+	// OnTransition is called with the message state every time the state of
+	// the message or one of its recipients changes, including the first poll.
+	OnTransition func(MessageState)
+}
+
+func (o WaitOptions) withDefaults() WaitOptions {
+	if o.PollInterval <= 0 {
+		o.PollInterval = DefaultWaitPollInterval
+	}
+	if o.MaxPollInterval <= 0 {
+		o.MaxPollInterval = DefaultWaitMaxPollInterval
+	}
+	if o.MaxPollInterval < o.PollInterval {
+		o.MaxPollInterval = o.PollInterval
+	}
+	if o.Backoff < 1 {
+		o.Backoff = DefaultWaitBackoff
+	}
+	if len(o.States) == 0 {
+		o.States = []ProcessingState{ProcessingStateDelivered, ProcessingStateFailed}
+	}
+	return o
+}
+
+// reached reports whether the message is in one of the target states.
+func (o WaitOptions) reached(state MessageState) bool {
+	if !o.PerRecipient {
+		return slices.Contains(o.States, state.State)
+	}
+
+	if len(state.Recipients) == 0 {
+		return false
+	}
+	for _, recipient := range state.Recipients {
+		if !slices.Contains(o.States, recipient.State) {
+			return false
+		}
+	}
+	return true
+}
+
+// failed reports whether the message failed while Failed is not a target
+// state, so the target can never be reached.
+func (o WaitOptions) failed(state MessageState) bool {
+	if slices.Contains(o.States, ProcessingStateFailed) {
+		return false
+	}
+
+	if !o.PerRecipient {
+		return state.State == ProcessingStateFailed
+	}
+	return slices.ContainsFunc(state.Recipients, func(r RecipientState) bool {
+		return r.State == ProcessingStateFailed
+	})
+}
+
+// WaitForState polls the message state until it reaches one of the target
+// states, see WaitOptions. Use ctx to limit the wait time.
+//
+// Transient errors, such as server or network errors, don't stop the polling.
+// It returns the last observed state together with ErrMessageFailed if the
+// message failed while waiting for other states, with the context error if
+// ctx is done first, or with the error of a poll that can't succeed later,
+// e.g. if the message is not found.
+//
+// Requires the ScopeMessagesRead scope.
+func (c *Client) WaitForState(ctx context.Context, messageID string, opts WaitOptions) (MessageState, error) {
+	opts = opts.withDefaults()
+
+	var (
+		last  MessageState
+		delay = opts.PollInterval
+	)
+	for poll := 0; ; poll++ {
+		if poll > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return last, fmt.Errorf("failed to wait for message state: %w", ctx.Err())
+			case <-timer.C:
+			}
+			delay = min(time.Duration(float64(delay)*opts.Backoff), opts.MaxPollInterval)
+		}
+
+		state, err := c.GetState(ctx, messageID)
+		if err != nil && rest.IsTransient(err) {
+			continue
+		}
+		if err != nil {
+			return last, err
+		}
+
+		if opts.OnTransition != nil && (poll == 0 || stateChanged(last, state)) {
+			opts.OnTransition(state)
+		}
+		last = state
+
+		if opts.reached(state) {
+			return state, nil
+		}
+		if opts.failed(state) {
+			return state, fmt.Errorf("%w: %s", ErrMessageFailed, state.ID)
+		}
+	}
+}
+
+// SendAndWait enqueues a message and waits for it to reach one of the target
+// states, see WaitForState.
+//
+// Requires the ScopeMessagesSend and ScopeMessagesRead scopes.
+func (c *Client) SendAndWait(
+	ctx context.Context,
+	message Message,
+	opts WaitOptions,
+	options ...SendOption,
+) (MessageState, error) {
+	opts = opts.withDefaults()
+
+	state, err := c.Send(ctx, message, options...)
+	if err != nil {
+		return state, err
+	}
+
+	if opts.reached(state) {
+		return state, nil
+	}
+
+	return c.WaitForState(ctx, state.ID, opts)
+}
+
+func stateChanged(prev, curr MessageState) bool {
+	if prev.State != curr.State || len(prev.Recipients) != len(curr.Recipients) {
+		return true
+	}
+
+	for i := range curr.Recipients {
+		if prev.Recipients[i].PhoneNumber != curr.Recipients[i].PhoneNumber ||
+			prev.Recipients[i].State != curr.Recipients[i].State {
+			return true
+		}
+	}
+	return false
+}
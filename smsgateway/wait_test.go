@@ -0,0 +1,230 @@
+package smsgateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/rest"
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+// statesHandler serves the given message states one poll at a time, repeating
+// the last one. A state without ID is served as `503 Service Unavailable`.
+func statesHandler(states ...smsgateway.MessageState) (http.HandlerFunc, *atomic.Int32) {
+	polls := new(atomic.Int32)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/messages" {
+			w.WriteHeader(http.StatusAccepted)
+			_ = json.NewEncoder(w).Encode(states[0])
+			return
+		}
+
+		n := int(polls.Add(1))
+		state := states[min(n, len(states))-1]
+		if state.ID == "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(state)
+	}, polls
+}
+
+func messageState(state smsgateway.ProcessingState, recipients ...smsgateway.ProcessingState) smsgateway.MessageState {
+	result := smsgateway.MessageState{ID: "123", State: state}
+	for i, s := range recipients {
+		result.Recipients = append(result.Recipients, smsgateway.RecipientState{
+			PhoneNumber: string(rune('1' + i)),
+			State:       s,
+		})
+	}
+	return result
+}
+
+func fastWaitOptions() smsgateway.WaitOptions {
+	return smsgateway.WaitOptions{
+		PollInterval:    time.Millisecond,
+		MaxPollInterval: 5 * time.Millisecond,
+		Backoff:         2,
+	}
+}
+
+func TestClient_WaitForState(t *testing.T) {
+	tests := []struct {
+		name        string
+		states      []smsgateway.MessageState
+		opts        func(*smsgateway.WaitOptions)
+		expected    smsgateway.ProcessingState
+		polls       int32
+		transitions int
+		expectErr   error
+	}{
+		{
+			name: "Delivered",
+			states: []smsgateway.MessageState{
+				messageState(smsgateway.ProcessingStatePending, smsgateway.ProcessingStatePending),
+				messageState(smsgateway.ProcessingStatePending, smsgateway.ProcessingStatePending),
+				messageState(smsgateway.ProcessingStateSent, smsgateway.ProcessingStateSent),
+				messageState(smsgateway.ProcessingStateDelivered, smsgateway.ProcessingStateDelivered),
+			},
+			expected:    smsgateway.ProcessingStateDelivered,
+			polls:       4,
+			transitions: 3,
+		},
+		{
+			name: "Failed is final by default",
+			states: []smsgateway.MessageState{
+				messageState(smsgateway.ProcessingStatePending),
+				messageState(smsgateway.ProcessingStateFailed),
+			},
+			expected:    smsgateway.ProcessingStateFailed,
+			polls:       2,
+			transitions: 2,
+		},
+		{
+			name: "Transient errors are retried",
+			states: []smsgateway.MessageState{
+				messageState(smsgateway.ProcessingStatePending),
+				{},
+				messageState(smsgateway.ProcessingStateDelivered),
+			},
+			expected:    smsgateway.ProcessingStateDelivered,
+			polls:       3,
+			transitions: 2,
+		},
+		{
+			name: "Custom target states",
+			states: []smsgateway.MessageState{
+				messageState(smsgateway.ProcessingStatePending),
+				messageState(smsgateway.ProcessingStateSent),
+			},
+			opts: func(o *smsgateway.WaitOptions) {
+				o.States = []smsgateway.ProcessingState{smsgateway.ProcessingStateSent}
+			},
+			expected:    smsgateway.ProcessingStateSent,
+			polls:       2,
+			transitions: 2,
+		},
+		{
+			name: "Failed while waiting for delivery",
+			states: []smsgateway.MessageState{
+				messageState(smsgateway.ProcessingStateSent),
+				messageState(smsgateway.ProcessingStateFailed),
+			},
+			opts: func(o *smsgateway.WaitOptions) {
+				o.States = []smsgateway.ProcessingState{smsgateway.ProcessingStateDelivered}
+			},
+			expected:    smsgateway.ProcessingStateFailed,
+			polls:       2,
+			transitions: 2,
+			expectErr:   smsgateway.ErrMessageFailed,
+		},
+		{
+			name: "Per recipient",
+			states: []smsgateway.MessageState{
+				messageState(
+					smsgateway.ProcessingStateSent,
+					smsgateway.ProcessingStateDelivered,
+					smsgateway.ProcessingStateSent,
+				),
+				messageState(
+					smsgateway.ProcessingStateDelivered,
+					smsgateway.ProcessingStateDelivered,
+					smsgateway.ProcessingStateSent,
+				),
+				messageState(
+					smsgateway.ProcessingStateDelivered,
+					smsgateway.ProcessingStateDelivered,
+					smsgateway.ProcessingStateFailed,
+				),
+			},
+			opts: func(o *smsgateway.WaitOptions) {
+				o.PerRecipient = true
+			},
+			expected:    smsgateway.ProcessingStateDelivered,
+			polls:       3,
+			transitions: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, polls := statesHandler(tt.states...)
+			client := newTestClient(t, handler)
+
+			transitions := 0
+			opts := fastWaitOptions()
+			opts.OnTransition = func(smsgateway.MessageState) { transitions++ }
+			if tt.opts != nil {
+				tt.opts(&opts)
+			}
+
+			state, err := client.WaitForState(context.Background(), "123", opts)
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("WaitForState() error = %v, want %v", err, tt.expectErr)
+			}
+			if state.State != tt.expected {
+				t.Errorf("expected state %s, got %s", tt.expected, state.State)
+			}
+			if got := polls.Load(); got != tt.polls {
+				t.Errorf("expected %d polls, got %d", tt.polls, got)
+			}
+			if transitions != tt.transitions {
+				t.Errorf("expected %d transitions, got %d", tt.transitions, transitions)
+			}
+		})
+	}
+}
+
+func TestClient_WaitForState_Timeout(t *testing.T) {
+	handler, _ := statesHandler(messageState(smsgateway.ProcessingStatePending))
+	client := newTestClient(t, handler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	state, err := client.WaitForState(ctx, "123", fastWaitOptions())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if state.State != smsgateway.ProcessingStatePending {
+		t.Errorf("expected last observed state, got %+v", state)
+	}
+}
+
+func TestClient_SendAndWait(t *testing.T) {
+	handler, polls := statesHandler(
+		messageState(smsgateway.ProcessingStatePending),
+		messageState(smsgateway.ProcessingStateDelivered),
+	)
+	client := newTestClient(t, handler)
+
+	state, err := client.SendAndWait(context.Background(), smsgateway.Message{
+		TextMessage:  &smsgateway.TextMessage{Text: "OTP: 1234"},
+		PhoneNumbers: []string{"+79990001234"},
+	}, fastWaitOptions())
+	if err != nil {
+		t.Fatalf("SendAndWait() unexpected error = %v", err)
+	}
+	if state.State != smsgateway.ProcessingStateDelivered {
+		t.Errorf("expected Delivered, got %s", state.State)
+	}
+	if got := polls.Load(); got != 2 {
+		t.Errorf("expected 2 polls, got %d", got)
+	}
+}
+
+func TestClient_WaitForState_NotFound(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	_, err := client.WaitForState(context.Background(), "123", fastWaitOptions())
+	if !errors.Is(err, rest.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", rest.ErrNotFound, err)
+	}
+}
@@ -0,0 +1,143 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// DedupStore keeps the IDs of processed webhook events.
+//
+// Implementations must be safe for concurrent use.
+type DedupStore interface {
+	// Seen reports whether the event was already processed.
+	Seen(ctx context.Context, id string) (bool, error)
+	// Mark records the event as processed.
+	Mark(ctx context.Context, id string) error
+}
+
+// DedupHandler is an http.Handler that skips webhook events already processed,
+// so deliveries retried by the device are handled only once.
+//
+// Events are keyed on Event.ID. Duplicates are acknowledged with `200 OK`
+// without calling the next handler. An event is marked as processed only when
+// the next handler replies with a 2xx status code, so failed deliveries are
+// processed again on retry. Concurrent deliveries of an event being processed
+// are rejected with `409 Conflict` for the device to retry them later.
+// Requests without an event ID are passed through.
+type DedupHandler struct {
+	store       DedupStore
+	next        http.Handler
+	maxBodySize int64
+
+	mu       sync.Mutex
+	inFlight map[string]struct{}
+}
+
+// NewDedupHandler creates a DedupHandler that records processed events in the
+// store and hands new events to next.
+func NewDedupHandler(store DedupStore, next http.Handler) *DedupHandler {
+	return &DedupHandler{
+		store:       store,
+		next:        next,
+		maxBodySize: DefaultMaxBodySize,
+
+		mu:       sync.Mutex{},
+		inFlight: make(map[string]struct{}),
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *DedupHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodySize))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	envelope := struct {
+		ID string `json:"id"`
+	}{}
+	if jsonErr := json.Unmarshal(body, &envelope); jsonErr != nil || envelope.ID == "" {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	if !h.acquire(envelope.ID) {
+		http.Error(w, "event is being processed", http.StatusConflict)
+		return
+	}
+	defer h.release(envelope.ID)
+
+	seen, err := h.store.Seen(r.Context(), envelope.ID)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if seen {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	rec := &statusRecorder{ResponseWriter: w, status: 0}
+	h.next.ServeHTTP(rec, r)
+
+	if rec.status == 0 || (rec.status >= 200 && rec.status < 300) {
+		// The response is already sent, a failure only leads to reprocessing
+		// of a retried delivery.
+		_ = h.store.Mark(r.Context(), envelope.ID)
+	}
+}
+
+func (h *DedupHandler) acquire(id string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.inFlight[id]; ok {
+		return false
+	}
+	h.inFlight[id] = struct{}{}
+	return true
+}
+
+func (h *DedupHandler) release(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.inFlight, id)
+}
+
+// statusRecorder captures the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	n, err := r.ResponseWriter.Write(b)
+	if err != nil {
+		return n, fmt.Errorf("failed to write response: %w", err)
+	}
+	return n, nil
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,225 @@
+package webhooks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultDedupTTL is how long processed event IDs are remembered by default.
+// It should exceed the time the device keeps retrying a delivery.
+const DefaultDedupTTL = 24 * time.Hour
+
+const dedupFileMode = 0o600
+
+// MemoryDedupStore is an in-memory DedupStore that forgets event IDs after a
+// TTL. Its state is lost on restart.
+type MemoryDedupStore struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	expiresAt map[string]time.Time
+	sweptAt   time.Time
+}
+
+// NewMemoryDedupStore creates a MemoryDedupStore that remembers event IDs for
+// ttl, DefaultDedupTTL if ttl is not positive.
+func NewMemoryDedupStore(ttl time.Duration) *MemoryDedupStore {
+	if ttl <= 0 {
+		ttl = DefaultDedupTTL
+	}
+
+	return &MemoryDedupStore{
+		ttl: ttl,
+		now: time.Now,
+
+		mu:        sync.Mutex{},
+		expiresAt: make(map[string]time.Time),
+		sweptAt:   time.Time{},
+	}
+}
+
+// Seen implements DedupStore.
+func (s *MemoryDedupStore) Seen(_ context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt, ok := s.expiresAt[id]
+	return ok && s.now().Before(expiresAt), nil
+}
+
+// Mark implements DedupStore.
+func (s *MemoryDedupStore) Mark(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(id, s.now().Add(s.ttl))
+	return nil
+}
+
+// set records the ID and removes expired entries at most once per TTL.
+func (s *MemoryDedupStore) set(id string, expiresAt time.Time) {
+	now := s.now()
+	if now.Sub(s.sweptAt) >= s.ttl {
+		for key, exp := range s.expiresAt {
+			if !now.Before(exp) {
+				delete(s.expiresAt, key)
+			}
+		}
+		s.sweptAt = now
+	}
+
+	s.expiresAt[id] = expiresAt
+}
+
+// FileDedupStore is a DedupStore persisted to a local append-only file, so
+// processed event IDs survive restarts.
+//
+// The file is compacted when the store is opened. FileDedupStore must not be
+// shared between processes.
+type FileDedupStore struct {
+	memory *MemoryDedupStore
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+type dedupRecord struct {
+	ID        string `json:"id"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// OpenFileDedupStore opens or creates a FileDedupStore at path that remembers
+// event IDs for ttl, DefaultDedupTTL if ttl is not positive.
+func OpenFileDedupStore(path string, ttl time.Duration) (*FileDedupStore, error) {
+	memory := NewMemoryDedupStore(ttl)
+
+	if err := loadDedupFile(path, memory); err != nil {
+		return nil, err
+	}
+	if err := compactDedupFile(path, memory); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, dedupFileMode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dedup store: %w", err)
+	}
+
+	return &FileDedupStore{
+		memory: memory,
+
+		mu:   sync.Mutex{},
+		file: file,
+	}, nil
+}
+
+// Seen implements DedupStore.
+func (s *FileDedupStore) Seen(ctx context.Context, id string) (bool, error) {
+	return s.memory.Seen(ctx, id)
+}
+
+// Mark implements DedupStore.
+func (s *FileDedupStore) Mark(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return ErrStoreClosed
+	}
+
+	line, err := json.Marshal(dedupRecord{ID: id, ExpiresAt: s.memory.now().Add(s.memory.ttl).Unix()})
+	if err != nil {
+		return fmt.Errorf("failed to encode dedup record: %w", err)
+	}
+	if _, writeErr := s.file.Write(append(line, '\n')); writeErr != nil {
+		return fmt.Errorf("failed to write dedup record: %w", writeErr)
+	}
+
+	return s.memory.Mark(ctx, id)
+}
+
+// Close closes the underlying file.
+func (s *FileDedupStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.file.Close()
+	s.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to close dedup store: %w", err)
+	}
+	return nil
+}
+
+func loadDedupFile(path string, memory *MemoryDedupStore) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open dedup store: %w", err)
+	}
+	defer file.Close()
+
+	now := memory.now()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		record := dedupRecord{}
+		if jsonErr := json.Unmarshal(scanner.Bytes(), &record); jsonErr != nil {
+			// A torn last line after a crash is skipped.
+			continue
+		}
+
+		if expiresAt := time.Unix(record.ExpiresAt, 0); now.Before(expiresAt) {
+			memory.set(record.ID, expiresAt)
+		}
+	}
+	if scanErr := scanner.Err(); scanErr != nil {
+		return fmt.Errorf("failed to read dedup store: %w", scanErr)
+	}
+
+	return nil
+}
+
+// compactDedupFile atomically rewrites the file with unexpired records only.
+func compactDedupFile(path string, memory *MemoryDedupStore) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to compact dedup store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for id, expiresAt := range memory.expiresAt {
+		if encErr := encoder.Encode(dedupRecord{ID: id, ExpiresAt: expiresAt.Unix()}); encErr != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("failed to compact dedup store: %w", encErr)
+		}
+	}
+
+	if flushErr := writer.Flush(); flushErr != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to compact dedup store: %w", flushErr)
+	}
+	if closeErr := tmp.Close(); closeErr != nil {
+		return fmt.Errorf("failed to compact dedup store: %w", closeErr)
+	}
+	if renameErr := os.Rename(tmp.Name(), path); renameErr != nil {
+		return fmt.Errorf("failed to compact dedup store: %w", renameErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package webhooks_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/smsgateway/webhooks"
+)
+
+func TestDedupHandler(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		calls  int
+		status = http.StatusInternalServerError
+	)
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		w.WriteHeader(status)
+	})
+
+	handler := webhooks.NewDedupHandler(webhooks.NewMemoryDedupStore(time.Hour), next)
+	const event = `{"id":"ev1","event":"sms:received","payload":{}}`
+
+	if rec := postEvent(handler, event); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected failed delivery to be passed through, got %d", rec.Code)
+	}
+
+	status = http.StatusOK
+	for range 3 {
+		if rec := postEvent(handler, event); rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected failed delivery and first retry to be processed, got %d calls", calls)
+	}
+
+	postEvent(handler, `{"id":"ev2","event":"sms:received","payload":{}}`)
+	postEvent(handler, `{"event":"sms:received","payload":{}}`)
+	postEvent(handler, `{"event":"sms:received","payload":{}}`)
+	if calls != 5 {
+		t.Errorf("expected new events and events without ID to be processed, got %d calls", calls)
+	}
+}
+
+func TestDedupHandler_Concurrent(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+
+	handler := webhooks.NewDedupHandler(webhooks.NewMemoryDedupStore(time.Hour), next)
+	const event = `{"id":"ev1","event":"sms:received","payload":{}}`
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postEvent(handler, event) }()
+
+	<-started
+	if rec := postEvent(handler, event); rec.Code != http.StatusConflict {
+		t.Errorf("expected concurrent duplicate to be rejected, got %d", rec.Code)
+	}
+	close(release)
+
+	if rec := <-done; rec.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestMemoryDedupStore_TTL(t *testing.T) {
+	ctx := context.Background()
+	store := webhooks.NewMemoryDedupStore(50 * time.Millisecond)
+
+	if err := store.Mark(ctx, "ev1"); err != nil {
+		t.Fatalf("Mark() unexpected error = %v", err)
+	}
+	if seen, _ := store.Seen(ctx, "ev1"); !seen {
+		t.Error("expected event to be seen")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if seen, _ := store.Seen(ctx, "ev1"); seen {
+		t.Error("expected event to expire")
+	}
+}
+
+func TestFileDedupStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "dedup.log")
+
+	store, err := webhooks.OpenFileDedupStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("OpenFileDedupStore() unexpected error = %v", err)
+	}
+	for _, id := range []string{"ev1", "ev2", "ev1"} {
+		if markErr := store.Mark(ctx, id); markErr != nil {
+			t.Fatalf("Mark() unexpected error = %v", markErr)
+		}
+	}
+	if closeErr := store.Close(); closeErr != nil {
+		t.Fatalf("Close() unexpected error = %v", closeErr)
+	}
+	if markErr := store.Mark(ctx, "ev3"); markErr == nil {
+		t.Error("Mark() after Close() expected error")
+	}
+
+	// Simulate an expired record and a torn write.
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.WriteString(`{"id":"old","exp":1}` + "\n" + `{"id":"torn`)
+	_ = file.Close()
+
+	store, err = webhooks.OpenFileDedupStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("OpenFileDedupStore() unexpected error = %v", err)
+	}
+	defer store.Close()
+
+	for id, expected := range map[string]bool{"ev1": true, "ev2": true, "old": false, "ev3": false} {
+		if seen, _ := store.Seen(ctx, id); seen != expected {
+			t.Errorf("Seen(%s) = %v, want %v", id, seen, expected)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("expected compacted file with 2 records, got %d:\n%s", lines, data)
+	}
+}
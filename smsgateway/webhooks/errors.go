@@ -0,0 +1,16 @@
+package webhooks
+
+import "errors"
+
+var (
+	ErrInvalidEvent = errors.New("invalid event")
+	ErrUnknownEvent = errors.New("unknown event")
+	ErrHandlerPanic = errors.New("handler panic")
+
+	ErrMissingSignature = errors.New("missing signature")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+	ErrStaleTimestamp   = errors.New("stale timestamp")
+
+	ErrStoreClosed = errors.New("store closed")
+)
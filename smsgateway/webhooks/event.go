@@ -0,0 +1,71 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+// Event is the envelope of a webhook request sent by the device.
+//
+// Payload holds the typed payload for the event type, e.g.
+// smsgateway.SmsReceivedPayload for `sms:received`. RawPayload keeps the
+// original JSON.
+type Event struct {
+	ID         string                  `json:"id"`        // Event ID, stays the same when the delivery is retried
+	WebhookID  string                  `json:"webhookId"` // Identifier of the webhook the event was sent for
+	DeviceID   string                  `json:"deviceId"`  // Identifier of the device that sent the event
+	Event      smsgateway.WebhookEvent `json:"event"`     // Type of the event
+	Payload    any                     `json:"-"`         // Typed payload
+	RawPayload json.RawMessage         `json:"payload"`   // Original JSON payload
+}
+
+//nolint:gochecknoglobals // lookup table
+var payloadDecoders = map[smsgateway.WebhookEvent]func(json.RawMessage) (any, error){
+	smsgateway.WebhookEventSmsReceived:     decodePayload[smsgateway.SmsReceivedPayload],
+	smsgateway.WebhookEventSmsDataReceived: decodePayload[smsgateway.SmsDataReceivedPayload],
+	smsgateway.WebhookEventSmsSent:         decodePayload[smsgateway.SmsSentPayload],
+	smsgateway.WebhookEventSmsDelivered:    decodePayload[smsgateway.SmsDeliveredPayload],
+	smsgateway.WebhookEventSmsFailed:       decodePayload[smsgateway.SmsFailedPayload],
+	smsgateway.WebhookEventSystemPing:      decodePayload[smsgateway.SystemPingPayload],
+	smsgateway.WebhookEventMmsReceived:     decodePayload[smsgateway.MmsReceivedPayload],
+	smsgateway.WebhookEventMmsDownloaded:   decodePayload[smsgateway.MmsDownloadedPayload],
+	smsgateway.WebhookEventAppStarted:      decodePayload[smsgateway.AppStartedPayload],
+}
+
+// ParseEvent decodes a webhook request body into an Event with a typed payload.
+//
+// It returns ErrUnknownEvent for event types without a known payload and
+// ErrInvalidEvent for malformed bodies.
+func ParseEvent(data []byte) (Event, error) {
+	event := Event{}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return event, fmt.Errorf("%w: %w", ErrInvalidEvent, err)
+	}
+
+	decode, ok := payloadDecoders[event.Event]
+	if !ok {
+		return event, fmt.Errorf("%w: %q", ErrUnknownEvent, event.Event)
+	}
+
+	payload, err := decode(event.RawPayload)
+	if err != nil {
+		return event, fmt.Errorf("%w: failed to decode %s payload: %w", ErrInvalidEvent, event.Event, err)
+	}
+	event.Payload = payload
+
+	return event, nil
+}
+
+func decodePayload[T any](raw json.RawMessage) (any, error) {
+	payload := new(T)
+	if len(raw) == 0 || string(raw) == "null" {
+		return *payload, nil
+	}
+
+	if err := json.Unmarshal(raw, payload); err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by ParseEvent
+	}
+	return *payload, nil
+}
@@ -0,0 +1,101 @@
+package webhooks_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/smsgateway"
+	"github.com/android-sms-gateway/client-go/smsgateway/webhooks"
+)
+
+func TestParseEvent(t *testing.T) {
+	receivedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		body      string
+		expected  any
+		expectErr error
+	}{
+		{
+			name: "SMS received",
+			body: `{"id":"ev1","webhookId":"wh1","deviceId":"dev1","event":"sms:received",` +
+				`"payload":{"messageId":"m1","phoneNumber":"+79990001234","message":"Hi","receivedAt":"2024-01-01T00:00:00Z"}}`,
+			expected: smsgateway.SmsReceivedPayload{
+				SmsEventPayload: smsgateway.SmsEventPayload{MessageID: "m1", PhoneNumber: "+79990001234"},
+				Message:         "Hi",
+				ReceivedAt:      receivedAt,
+			},
+		},
+		{
+			name: "SMS failed",
+			body: `{"id":"ev1","event":"sms:failed","payload":{"messageId":"m1","reason":"timeout"}}`,
+			expected: smsgateway.SmsFailedPayload{
+				SmsEventPayload: smsgateway.SmsEventPayload{MessageID: "m1"},
+				Reason:          "timeout",
+			},
+		},
+		{
+			name: "MMS downloaded",
+			body: `{"id":"ev1","event":"mms:downloaded","payload":{"messageId":"m1","attachments":[{"partId":1,"contentType":"image/jpeg"}]}}`,
+			expected: smsgateway.MmsDownloadedPayload{
+				SmsEventPayload: smsgateway.SmsEventPayload{MessageID: "m1"},
+				Attachments:     []smsgateway.MmsDownloadedAttachment{{PartID: 1, ContentType: "image/jpeg"}},
+			},
+		},
+		{
+			name:     "App started",
+			body:     `{"id":"ev1","event":"app:started","payload":{}}`,
+			expected: smsgateway.AppStartedPayload{},
+		},
+		{
+			name:     "System ping without payload",
+			body:     `{"id":"ev1","event":"system:ping"}`,
+			expected: smsgateway.SystemPingPayload{},
+		},
+		{
+			name:      "Unknown event",
+			body:      `{"id":"ev1","event":"sms:unknown","payload":{}}`,
+			expectErr: webhooks.ErrUnknownEvent,
+		},
+		{
+			name:      "Malformed body",
+			body:      `{"id":`,
+			expectErr: webhooks.ErrInvalidEvent,
+		},
+		{
+			name:      "Malformed payload",
+			body:      `{"id":"ev1","event":"sms:received","payload":{"message":1}}`,
+			expectErr: webhooks.ErrInvalidEvent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := webhooks.ParseEvent([]byte(tt.body))
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("ParseEvent() error = %v, want %v", err, tt.expectErr)
+			}
+			if tt.expectErr != nil {
+				return
+			}
+
+			if event.ID != "ev1" {
+				t.Errorf("expected event ID %q, got %q", "ev1", event.ID)
+			}
+			if !reflect.DeepEqual(event.Payload, tt.expected) {
+				t.Errorf("ParseEvent() payload = %#v, want %#v", event.Payload, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseEvent_AllEventTypes(t *testing.T) {
+	for _, eventType := range smsgateway.WebhookEventTypes() {
+		if _, err := webhooks.ParseEvent([]byte(`{"event":"` + eventType + `","payload":{}}`)); err != nil {
+			t.Errorf("ParseEvent(%s) unexpected error = %v", eventType, err)
+		}
+	}
+}
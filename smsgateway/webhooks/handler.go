@@ -0,0 +1,153 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Signature" // Header with the hex-encoded HMAC-SHA256 signature
+	TimestampHeader = "X-Timestamp" // Header with the Unix timestamp the request was signed at
+
+	DefaultReplayWindow = 5 * time.Minute // Default maximum age of a signed request
+	DefaultMaxBodySize  = 1 << 20         // Default maximum size of a webhook body, 1 MiB
+)
+
+// HandlerOption configures a Handler.
+type HandlerOption func(*Handler)
+
+// WithReplayWindow sets the maximum difference between the signing timestamp
+// and the current time. Requests outside the window are rejected as replays.
+// Defaults to DefaultReplayWindow.
+func WithReplayWindow(window time.Duration) HandlerOption {
+	return func(h *Handler) {
+		h.window = window
+	}
+}
+
+// WithMaxBodySize sets the maximum size of a webhook body in bytes.
+// Defaults to DefaultMaxBodySize.
+func WithMaxBodySize(size int64) HandlerOption {
+	return func(h *Handler) {
+		h.maxBodySize = size
+	}
+}
+
+// Handler is an http.Handler that verifies webhook signatures before passing
+// requests to the next handler.
+//
+// The device signs webhooks when `SettingsWebhooks.SigningKey` is set: the
+// signature is the hex-encoded HMAC-SHA256 of the body followed by the
+// timestamp, sent in the SignatureHeader and TimestampHeader headers.
+//
+// Requests with a missing or invalid signature or a timestamp outside the
+// replay window are rejected with `401 Unauthorized`, malformed timestamps
+// with `400 Bad Request`. The next handler receives the original body.
+type Handler struct {
+	key         []byte
+	next        http.Handler
+	window      time.Duration
+	maxBodySize int64
+	now         func() time.Time
+}
+
+// NewHandler creates a Handler that verifies requests with the signing key and
+// hands verified requests to next.
+func NewHandler(signingKey string, next http.Handler, options ...HandlerOption) *Handler {
+	h := &Handler{
+		key:         []byte(signingKey),
+		next:        next,
+		window:      DefaultReplayWindow,
+		maxBodySize: DefaultMaxBodySize,
+		now:         time.Now,
+	}
+
+	for _, option := range options {
+		option(h)
+	}
+
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	err = verify(h.key, body, r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader), h.now(), h.window)
+	switch {
+	case errors.Is(err, ErrInvalidTimestamp):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	h.next.ServeHTTP(w, r)
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 signature of the body and timestamp,
+// as computed by the device.
+func Sign(signingKey string, body []byte, timestamp string) string {
+	return hex.EncodeToString(computeMAC([]byte(signingKey), body, timestamp))
+}
+
+// Verify checks the signature of the body and timestamp in constant time, and
+// that the timestamp is within the replay window around now.
+func Verify(signingKey string, body []byte, timestamp, signature string, now time.Time, window time.Duration) error {
+	return verify([]byte(signingKey), body, timestamp, signature, now, window)
+}
+
+func verify(key, body []byte, timestamp, signature string, now time.Time, window time.Duration) error {
+	if signature == "" || timestamp == "" {
+		return ErrMissingSignature
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidTimestamp, timestamp)
+	}
+
+	actual, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(computeMAC(key, body, timestamp), actual) {
+		return ErrInvalidSignature
+	}
+
+	if age := now.Sub(time.Unix(unix, 0)); age > window || age < -window {
+		return fmt.Errorf("%w: outside of %s replay window", ErrStaleTimestamp, window)
+	}
+
+	return nil
+}
+
+func computeMAC(key, body []byte, timestamp string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	mac.Write([]byte(timestamp))
+	return mac.Sum(nil)
+}
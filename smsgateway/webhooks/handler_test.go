@@ -0,0 +1,172 @@
+package webhooks_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/smsgateway/webhooks"
+)
+
+const signingKey = "secret"
+
+func newSignedRequest(body string, timestamp time.Time) *http.Request {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	req.Header.Set(webhooks.TimestampHeader, ts)
+	req.Header.Set(webhooks.SignatureHeader, webhooks.Sign(signingKey, []byte(body), ts))
+	return req
+}
+
+func TestHandler(t *testing.T) {
+	const body = `{"event":"system:ping"}`
+
+	tests := []struct {
+		name     string
+		request  func() *http.Request
+		options  []webhooks.HandlerOption
+		expected int
+	}{
+		{
+			name:     "Valid signature",
+			request:  func() *http.Request { return newSignedRequest(body, time.Now()) },
+			expected: http.StatusOK,
+		},
+		{
+			name: "Uppercase signature",
+			request: func() *http.Request {
+				req := newSignedRequest(body, time.Now())
+				req.Header.Set(webhooks.SignatureHeader, strings.ToUpper(req.Header.Get(webhooks.SignatureHeader)))
+				return req
+			},
+			expected: http.StatusOK,
+		},
+		{
+			name: "Unsigned",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+			},
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name: "Tampered body",
+			request: func() *http.Request {
+				req := newSignedRequest(body, time.Now())
+				req.Body = io.NopCloser(strings.NewReader(`{"event":"sms:received"}`))
+				return req
+			},
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name: "Wrong key",
+			request: func() *http.Request {
+				req := newSignedRequest(body, time.Now())
+				req.Header.Set(webhooks.SignatureHeader, webhooks.Sign("other", []byte(body), req.Header.Get(webhooks.TimestampHeader)))
+				return req
+			},
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "Stale",
+			request:  func() *http.Request { return newSignedRequest(body, time.Now().Add(-10*time.Minute)) },
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "From the future",
+			request:  func() *http.Request { return newSignedRequest(body, time.Now().Add(10*time.Minute)) },
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "Custom replay window",
+			request:  func() *http.Request { return newSignedRequest(body, time.Now().Add(-10*time.Minute)) },
+			options:  []webhooks.HandlerOption{webhooks.WithReplayWindow(time.Hour)},
+			expected: http.StatusOK,
+		},
+		{
+			name: "Malformed timestamp",
+			request: func() *http.Request {
+				req := newSignedRequest(body, time.Now())
+				req.Header.Set(webhooks.TimestampHeader, "yesterday")
+				return req
+			},
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "Body too large",
+			request:  func() *http.Request { return newSignedRequest(body, time.Now()) },
+			options:  []webhooks.HandlerOption{webhooks.WithMaxBodySize(8)},
+			expected: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "Wrong method",
+			request:  func() *http.Request { return httptest.NewRequest(http.MethodGet, "/webhook", nil) },
+			expected: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				received = string(data)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			rec := httptest.NewRecorder()
+			webhooks.NewHandler(signingKey, next, tt.options...).ServeHTTP(rec, tt.request())
+
+			if rec.Code != tt.expected {
+				t.Errorf("expected status %d, got %d: %s", tt.expected, rec.Code, rec.Body.String())
+			}
+			if tt.expected == http.StatusOK && received != body {
+				t.Errorf("expected next handler to receive %q, got %q", body, received)
+			}
+			if tt.expected != http.StatusOK && received != "" {
+				t.Error("next handler must not be called for rejected requests")
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	signature := webhooks.Sign(signingKey, body, ts)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		expected  error
+	}{
+		{name: "Valid", timestamp: ts, signature: signature, expected: nil},
+		{name: "Missing signature", timestamp: ts, signature: "", expected: webhooks.ErrMissingSignature},
+		{name: "Missing timestamp", timestamp: "", signature: signature, expected: webhooks.ErrMissingSignature},
+		{name: "Not hex", timestamp: ts, signature: "zz", expected: webhooks.ErrInvalidSignature},
+		{name: "Invalid timestamp", timestamp: "now", signature: signature, expected: webhooks.ErrInvalidTimestamp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := webhooks.Verify(signingKey, body, tt.timestamp, tt.signature, now, time.Minute)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.expected)
+			}
+		})
+	}
+
+	if err := webhooks.Verify(signingKey, body, ts, signature, now.Add(time.Hour), time.Minute); !errors.Is(
+		err,
+		webhooks.ErrStaleTimestamp,
+	) {
+		t.Errorf("Verify() error = %v, want %v", err, webhooks.ErrStaleTimestamp)
+	}
+}
@@ -0,0 +1,188 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+// EventHandlerFunc handles a webhook event of any type.
+type EventHandlerFunc func(ctx context.Context, event Event) error
+
+// StatusError is returned by event handlers to reply with a specific HTTP
+// status code, e.g. `400 Bad Request` for events that must not be retried.
+type StatusError struct {
+	Code int   // HTTP status code, `500 Internal Server Error` if not a valid final status
+	Err  error // Underlying error
+}
+
+func (e *StatusError) Error() string {
+	if e.Err == nil {
+		return http.StatusText(e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// Router is an http.Handler that parses webhook events and dispatches them to
+// callbacks registered for their type.
+//
+// A callback returning nil results in `200 OK`. A callback returning an error
+// or panicking results in `500 Internal Server Error`, so the device retries
+// the delivery according to `SettingsWebhooks.RetryCount`; return a
+// *StatusError to reply with another status code. Events without a callback
+// are passed to the catch-all callback, if any, and acknowledged otherwise.
+//
+// Router doesn't verify signatures; wrap it with NewHandler to do so.
+type Router struct {
+	handlers    map[smsgateway.WebhookEvent]EventHandlerFunc
+	fallback    EventHandlerFunc
+	onError     func(r *http.Request, err error)
+	maxBodySize int64
+}
+
+// NewRouter creates an empty Router.
+func NewRouter() *Router {
+	return &Router{
+		handlers:    make(map[smsgateway.WebhookEvent]EventHandlerFunc),
+		fallback:    nil,
+		onError:     nil,
+		maxBodySize: DefaultMaxBodySize,
+	}
+}
+
+// OnSmsReceived registers the callback for `sms:received` events.
+func (r *Router) OnSmsReceived(fn func(context.Context, Event, smsgateway.SmsReceivedPayload) error) {
+	on(r, smsgateway.WebhookEventSmsReceived, fn)
+}
+
+// OnSmsDataReceived registers the callback for `sms:data-received` events.
+func (r *Router) OnSmsDataReceived(fn func(context.Context, Event, smsgateway.SmsDataReceivedPayload) error) {
+	on(r, smsgateway.WebhookEventSmsDataReceived, fn)
+}
+
+// OnSmsSent registers the callback for `sms:sent` events.
+func (r *Router) OnSmsSent(fn func(context.Context, Event, smsgateway.SmsSentPayload) error) {
+	on(r, smsgateway.WebhookEventSmsSent, fn)
+}
+
+// OnSmsDelivered registers the callback for `sms:delivered` events.
+func (r *Router) OnSmsDelivered(fn func(context.Context, Event, smsgateway.SmsDeliveredPayload) error) {
+	on(r, smsgateway.WebhookEventSmsDelivered, fn)
+}
+
+// OnSmsFailed registers the callback for `sms:failed` events.
+func (r *Router) OnSmsFailed(fn func(context.Context, Event, smsgateway.SmsFailedPayload) error) {
+	on(r, smsgateway.WebhookEventSmsFailed, fn)
+}
+
+// OnMmsReceived registers the callback for `mms:received` events.
+func (r *Router) OnMmsReceived(fn func(context.Context, Event, smsgateway.MmsReceivedPayload) error) {
+	on(r, smsgateway.WebhookEventMmsReceived, fn)
+}
+
+// OnMmsDownloaded registers the callback for `mms:downloaded` events.
+func (r *Router) OnMmsDownloaded(fn func(context.Context, Event, smsgateway.MmsDownloadedPayload) error) {
+	on(r, smsgateway.WebhookEventMmsDownloaded, fn)
+}
+
+// OnAppStarted registers the callback for `app:started` events.
+func (r *Router) OnAppStarted(fn func(context.Context, Event, smsgateway.AppStartedPayload) error) {
+	on(r, smsgateway.WebhookEventAppStarted, fn)
+}
+
+// OnSystemPing registers the callback for `system:ping` events.
+func (r *Router) OnSystemPing(fn func(context.Context, Event, smsgateway.SystemPingPayload) error) {
+	on(r, smsgateway.WebhookEventSystemPing, fn)
+}
+
+// OnAny registers the catch-all callback for events without a dedicated
+// callback, including event types unknown to this package. The payload of
+// unknown events is nil, use Event.RawPayload instead.
+func (r *Router) OnAny(fn EventHandlerFunc) {
+	r.fallback = fn
+}
+
+// OnError registers a callback notified about every request that failed, e.g.
+// for logging.
+func (r *Router) OnError(fn func(r *http.Request, err error)) {
+	r.onError = fn
+}
+
+// ServeHTTP implements http.Handler.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, r.maxBodySize))
+	if err != nil {
+		r.fail(w, req, &StatusError{Code: http.StatusBadRequest, Err: fmt.Errorf("failed to read body: %w", err)})
+		return
+	}
+
+	event, err := ParseEvent(body)
+	if err != nil && !errors.Is(err, ErrUnknownEvent) {
+		r.fail(w, req, &StatusError{Code: http.StatusBadRequest, Err: err})
+		return
+	}
+
+	if handlerErr := r.dispatch(req.Context(), event); handlerErr != nil {
+		r.fail(w, req, handlerErr)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *Router) dispatch(ctx context.Context, event Event) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("%w: %v", ErrHandlerPanic, rec)
+		}
+	}()
+
+	if handler, ok := r.handlers[event.Event]; ok {
+		return handler(ctx, event)
+	}
+	if r.fallback != nil {
+		return r.fallback(ctx, event)
+	}
+	return nil
+}
+
+func (r *Router) fail(w http.ResponseWriter, req *http.Request, err error) {
+	if r.onError != nil {
+		r.onError(req, err)
+	}
+
+	code := http.StatusInternalServerError
+	// WriteHeader panics on codes outside of 100-999, and informational codes
+	// are not a response.
+	if statusErr := new(StatusError); errors.As(err, &statusErr) && statusErr.Code >= 200 && statusErr.Code <= 999 {
+		code = statusErr.Code
+	}
+	http.Error(w, http.StatusText(code), code)
+}
+
+func on[T any](r *Router, eventType smsgateway.WebhookEvent, fn func(context.Context, Event, T) error) {
+	r.handlers[eventType] = func(ctx context.Context, event Event) error {
+		payload, ok := event.Payload.(T)
+		if !ok {
+			return &StatusError{
+				Code: http.StatusBadRequest,
+				Err:  fmt.Errorf("%w: unexpected %s payload type %T", ErrInvalidEvent, eventType, event.Payload),
+			}
+		}
+		return fn(ctx, event, payload)
+	}
+}
@@ -0,0 +1,205 @@
+package webhooks_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/android-sms-gateway/client-go/smsgateway"
+	"github.com/android-sms-gateway/client-go/smsgateway/webhooks"
+)
+
+var errTemporary = errors.New("database unavailable")
+
+func postEvent(handler http.Handler, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body)))
+	return rec
+}
+
+func TestRouter_Dispatch(t *testing.T) {
+	router := webhooks.NewRouter()
+	called := map[smsgateway.WebhookEvent]int{}
+	record := func(event webhooks.Event) error {
+		called[event.Event]++
+		return nil
+	}
+
+	router.OnSmsReceived(func(_ context.Context, e webhooks.Event, p smsgateway.SmsReceivedPayload) error {
+		if p.Message != "Hi" {
+			t.Errorf("expected message %q, got %q", "Hi", p.Message)
+		}
+		return record(e)
+	})
+	router.OnSmsDataReceived(func(_ context.Context, e webhooks.Event, _ smsgateway.SmsDataReceivedPayload) error {
+		return record(e)
+	})
+	router.OnSmsSent(func(_ context.Context, e webhooks.Event, _ smsgateway.SmsSentPayload) error {
+		return record(e)
+	})
+	router.OnSmsDelivered(func(_ context.Context, e webhooks.Event, _ smsgateway.SmsDeliveredPayload) error {
+		return record(e)
+	})
+	router.OnSmsFailed(func(_ context.Context, e webhooks.Event, _ smsgateway.SmsFailedPayload) error {
+		return record(e)
+	})
+	router.OnMmsReceived(func(_ context.Context, e webhooks.Event, _ smsgateway.MmsReceivedPayload) error {
+		return record(e)
+	})
+	router.OnMmsDownloaded(func(_ context.Context, e webhooks.Event, _ smsgateway.MmsDownloadedPayload) error {
+		return record(e)
+	})
+	router.OnAppStarted(func(_ context.Context, e webhooks.Event, _ smsgateway.AppStartedPayload) error {
+		return record(e)
+	})
+	router.OnSystemPing(func(_ context.Context, e webhooks.Event, _ smsgateway.SystemPingPayload) error {
+		return record(e)
+	})
+
+	for _, eventType := range smsgateway.WebhookEventTypes() {
+		payload := `{}`
+		if eventType == smsgateway.WebhookEventSmsReceived {
+			payload = `{"message":"Hi"}`
+		}
+
+		rec := postEvent(router, `{"id":"1","event":"`+eventType+`","payload":`+payload+`}`)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: expected status 200, got %d", eventType, rec.Code)
+		}
+		if called[eventType] != 1 {
+			t.Errorf("%s: expected callback to be called once, got %d", eventType, called[eventType])
+		}
+	}
+}
+
+func TestRouter_Status(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(*webhooks.Router)
+		body     string
+		expected int
+	}{
+		{
+			name:     "Unhandled event is acknowledged",
+			setup:    func(*webhooks.Router) {},
+			body:     `{"id":"1","event":"sms:sent","payload":{}}`,
+			expected: http.StatusOK,
+		},
+		{
+			name: "Callback error",
+			setup: func(r *webhooks.Router) {
+				r.OnSmsSent(func(context.Context, webhooks.Event, smsgateway.SmsSentPayload) error {
+					return errTemporary
+				})
+			},
+			body:     `{"id":"1","event":"sms:sent","payload":{}}`,
+			expected: http.StatusInternalServerError,
+		},
+		{
+			name: "Status error",
+			setup: func(r *webhooks.Router) {
+				r.OnSmsSent(func(context.Context, webhooks.Event, smsgateway.SmsSentPayload) error {
+					return &webhooks.StatusError{Code: http.StatusUnprocessableEntity, Err: errTemporary}
+				})
+			},
+			body:     `{"id":"1","event":"sms:sent","payload":{}}`,
+			expected: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "Status error without code",
+			setup: func(r *webhooks.Router) {
+				r.OnSmsSent(func(context.Context, webhooks.Event, smsgateway.SmsSentPayload) error {
+					return &webhooks.StatusError{}
+				})
+			},
+			body:     `{"id":"1","event":"sms:sent","payload":{}}`,
+			expected: http.StatusInternalServerError,
+		},
+		{
+			name: "Status error with invalid code",
+			setup: func(r *webhooks.Router) {
+				r.OnSmsSent(func(context.Context, webhooks.Event, smsgateway.SmsSentPayload) error {
+					return &webhooks.StatusError{Code: 1000, Err: errTemporary}
+				})
+			},
+			body:     `{"id":"1","event":"sms:sent","payload":{}}`,
+			expected: http.StatusInternalServerError,
+		},
+		{
+			name: "Panic",
+			setup: func(r *webhooks.Router) {
+				r.OnSmsSent(func(context.Context, webhooks.Event, smsgateway.SmsSentPayload) error {
+					panic("boom")
+				})
+			},
+			body:     `{"id":"1","event":"sms:sent","payload":{}}`,
+			expected: http.StatusInternalServerError,
+		},
+		{
+			name: "Catch-all for unknown event",
+			setup: func(r *webhooks.Router) {
+				r.OnAny(func(_ context.Context, e webhooks.Event) error {
+					if e.Event != "sms:future" || e.Payload != nil || string(e.RawPayload) != `{"a":1}` {
+						return &webhooks.StatusError{Code: http.StatusTeapot, Err: nil}
+					}
+					return nil
+				})
+			},
+			body:     `{"id":"1","event":"sms:future","payload":{"a":1}}`,
+			expected: http.StatusOK,
+		},
+		{
+			name: "Catch-all error",
+			setup: func(r *webhooks.Router) {
+				r.OnAny(func(context.Context, webhooks.Event) error { return errTemporary })
+			},
+			body:     `{"id":"1","event":"sms:sent","payload":{}}`,
+			expected: http.StatusInternalServerError,
+		},
+		{
+			name:     "Malformed body",
+			setup:    func(*webhooks.Router) {},
+			body:     `not json`,
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := webhooks.NewRouter()
+			tt.setup(router)
+
+			var reported error
+			router.OnError(func(_ *http.Request, err error) { reported = err })
+
+			rec := postEvent(router, tt.body)
+			if rec.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, rec.Code)
+			}
+			if (tt.expected != http.StatusOK) != (reported != nil) {
+				t.Errorf("unexpected reported error: %v", reported)
+			}
+		})
+	}
+}
+
+func TestRouter_WithHandler(t *testing.T) {
+	router := webhooks.NewRouter()
+	received := false
+	router.OnSystemPing(func(context.Context, webhooks.Event, smsgateway.SystemPingPayload) error {
+		received = true
+		return nil
+	})
+
+	handler := webhooks.NewHandler(signingKey, router)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newSignedRequest(`{"id":"1","event":"system:ping","payload":{}}`, time.Now()))
+
+	if rec.Code != http.StatusOK || !received {
+		t.Errorf("expected signed event to be dispatched, got status %d", rec.Code)
+	}
+}
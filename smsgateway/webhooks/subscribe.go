@@ -0,0 +1,74 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/android-sms-gateway/client-go/smsgateway"
+)
+
+// Subscribe registers webhooks for the URL and events, all events if none are
+// given. Events already registered for the URL are skipped, so Subscribe can
+// be called on every start. It returns the webhooks registered for the URL.
+//
+// Requires the ScopeWebhooksList and ScopeWebhooksWrite scopes.
+func Subscribe(ctx context.Context, client *smsgateway.Client, url string, events ...EventType) ([]Webhook, error) {
+	if len(events) == 0 {
+		events = EventTypes()
+	}
+	for _, event := range events {
+		if !IsValidEventType(event) {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownEvent, event)
+		}
+	}
+
+	current, err := client.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // already wrapped by the client
+	}
+
+	subscribed := make([]Webhook, 0, len(events))
+	for _, event := range events {
+		idx := slices.IndexFunc(current, func(w Webhook) bool {
+			return w.URL == url && w.Event == event && w.DeviceID == nil
+		})
+		if idx >= 0 {
+			subscribed = append(subscribed, current[idx])
+			continue
+		}
+
+		webhook, registerErr := client.RegisterWebhook(ctx, Webhook{ID: "", DeviceID: nil, URL: url, Event: event})
+		if registerErr != nil {
+			return subscribed, registerErr //nolint:wrapcheck // already wrapped by the client
+		}
+		subscribed = append(subscribed, webhook)
+	}
+
+	return subscribed, nil
+}
+
+// Unsubscribe deletes the webhooks registered for the URL and events, all
+// events if none are given. It returns the deleted webhooks.
+//
+// Requires the ScopeWebhooksList and ScopeWebhooksDelete scopes.
+func Unsubscribe(ctx context.Context, client *smsgateway.Client, url string, events ...EventType) ([]Webhook, error) {
+	current, err := client.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // already wrapped by the client
+	}
+
+	var deleted []Webhook
+	for _, webhook := range current {
+		if webhook.URL != url || (len(events) > 0 && !slices.Contains(events, webhook.Event)) {
+			continue
+		}
+
+		if deleteErr := client.DeleteWebhook(ctx, webhook.ID); deleteErr != nil {
+			return deleted, deleteErr //nolint:wrapcheck // already wrapped by the client
+		}
+		deleted = append(deleted, webhook)
+	}
+
+	return deleted, nil
+}
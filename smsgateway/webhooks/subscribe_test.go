@@ -0,0 +1,117 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/android-sms-gateway/client-go/smsgateway"
+	"github.com/android-sms-gateway/client-go/smsgateway/webhooks"
+)
+
+func newWebhooksClient(t *testing.T, initial ...webhooks.Webhook) (*smsgateway.Client, func() []webhooks.Webhook) {
+	t.Helper()
+
+	var (
+		mu    sync.Mutex
+		hooks = initial
+		id    = len(initial)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(hooks)
+		case http.MethodPost:
+			webhook := webhooks.Webhook{}
+			_ = json.NewDecoder(r.Body).Decode(&webhook)
+			id++
+			webhook.ID = fmt.Sprintf("wh%d", id)
+			hooks = append(hooks, webhook)
+			_ = json.NewEncoder(w).Encode(webhook)
+		case http.MethodDelete:
+			target := strings.TrimPrefix(r.URL.Path, "/webhooks/")
+			for i, webhook := range hooks {
+				if webhook.ID == target {
+					hooks = append(hooks[:i], hooks[i+1:]...)
+					break
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := smsgateway.NewClient(smsgateway.Config{BaseURL: server.URL, User: "user", Password: "pass"})
+	return client, func() []webhooks.Webhook {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]webhooks.Webhook(nil), hooks...)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	const url = "https://example.com/hook"
+
+	client, registered := newWebhooksClient(t,
+		webhooks.Webhook{ID: "wh0", URL: url, Event: webhooks.EventTypeSmsReceived},
+		webhooks.Webhook{ID: "other", URL: "https://other.example.com", Event: webhooks.EventTypeSmsReceived},
+	)
+
+	subscribed, err := webhooks.Subscribe(context.Background(), client, url)
+	if err != nil {
+		t.Fatalf("Subscribe() unexpected error = %v", err)
+	}
+	if len(subscribed) != len(webhooks.EventTypes()) {
+		t.Errorf("expected %d webhooks, got %d", len(webhooks.EventTypes()), len(subscribed))
+	}
+	if subscribed[0].ID != "wh0" {
+		t.Errorf("expected existing webhook to be reused, got %s", subscribed[0].ID)
+	}
+	if got := len(registered()); got != len(webhooks.EventTypes())+1 {
+		t.Errorf("expected %d webhooks on server, got %d", len(webhooks.EventTypes())+1, got)
+	}
+
+	if _, err = webhooks.Subscribe(context.Background(), client, url); err != nil {
+		t.Fatalf("Subscribe() unexpected error = %v", err)
+	}
+	if got := len(registered()); got != len(webhooks.EventTypes())+1 {
+		t.Errorf("expected repeated Subscribe() to be a no-op, got %d webhooks", got)
+	}
+
+	deleted, err := webhooks.Unsubscribe(context.Background(), client, url, webhooks.EventTypeSystemPing)
+	if err != nil || len(deleted) != 1 {
+		t.Fatalf("Unsubscribe() = %v, %v, want 1 deleted webhook", deleted, err)
+	}
+
+	deleted, err = webhooks.Unsubscribe(context.Background(), client, url)
+	if err != nil {
+		t.Fatalf("Unsubscribe() unexpected error = %v", err)
+	}
+	if len(deleted) != len(webhooks.EventTypes())-1 {
+		t.Errorf("expected %d deleted webhooks, got %d", len(webhooks.EventTypes())-1, len(deleted))
+	}
+	if remaining := registered(); len(remaining) != 1 || remaining[0].ID != "other" {
+		t.Errorf("expected only other webhooks to remain, got %+v", remaining)
+	}
+}
+
+func TestSubscribe_InvalidEvent(t *testing.T) {
+	client, registered := newWebhooksClient(t)
+
+	_, err := webhooks.Subscribe(context.Background(), client, "https://example.com", "sms:unknown")
+	if !errors.Is(err, webhooks.ErrUnknownEvent) {
+		t.Errorf("expected ErrUnknownEvent, got %v", err)
+	}
+	if len(registered()) != 0 {
+		t.Error("expected no webhooks to be registered")
+	}
+}
@@ -1,27 +1,48 @@
+// Package webhooks helps to receive webhooks sent by the device: it verifies
+// signatures, parses and routes events, and deduplicates retried deliveries.
 package webhooks
 
 import "github.com/android-sms-gateway/client-go/smsgateway"
 
-// EventType is deprecated: use smsgateway package instead.
+// EventType is the type of webhook event.
 type EventType = smsgateway.WebhookEvent
 
 const (
-	// Deprecated: use smsgateway package instead.
-	EventTypeSmsReceived EventType = smsgateway.WebhookEventSmsReceived
-	// Deprecated: use smsgateway package instead.
-	EventTypeSmsSent EventType = smsgateway.WebhookEventSmsSent
-	// Deprecated: use smsgateway package instead.
-	EventTypeSmsDelivered EventType = smsgateway.WebhookEventSmsDelivered
-	// Deprecated: use smsgateway package instead.
-	EventTypeSmsFailed EventType = smsgateway.WebhookEventSmsFailed
-	// Deprecated: use smsgateway package instead.
-	EventTypeSystemPing EventType = smsgateway.WebhookEventSystemPing
+	EventTypeSmsReceived     EventType = smsgateway.WebhookEventSmsReceived     // Triggered when an SMS is received.
+	EventTypeSmsDataReceived EventType = smsgateway.WebhookEventSmsDataReceived // Triggered when a data SMS is received.
+	EventTypeSmsSent         EventType = smsgateway.WebhookEventSmsSent         // Triggered when an SMS is sent.
+	EventTypeSmsDelivered    EventType = smsgateway.WebhookEventSmsDelivered    // Triggered when an SMS is delivered.
+	EventTypeSmsFailed       EventType = smsgateway.WebhookEventSmsFailed       // Triggered when an SMS processing fails.
+	EventTypeSystemPing      EventType = smsgateway.WebhookEventSystemPing      // Triggered when the device pings the server.
+	EventTypeMmsReceived     EventType = smsgateway.WebhookEventMmsReceived     // Triggered when an MMS is received.
+	EventTypeMmsDownloaded   EventType = smsgateway.WebhookEventMmsDownloaded   // Triggered when an MMS is downloaded.
+	EventTypeAppStarted      EventType = smsgateway.WebhookEventAppStarted      // Triggered when the application is started.
 )
 
-// Deprecated: use smsgateway package instead.
+// EventTypes returns all supported webhook event types.
+func EventTypes() []EventType {
+	return smsgateway.WebhookEventTypes()
+}
+
+// IsValidEventType checks if the webhook event is a valid type.
 func IsValidEventType(e EventType) bool {
 	return smsgateway.IsValidWebhookEvent(e)
 }
 
-// Webhook is deprecated: use smsgateway package instead.
+// Webhook is a webhook registration.
 type Webhook = smsgateway.Webhook
+
+// Payloads of webhook events.
+type (
+	SmsEventPayload         = smsgateway.SmsEventPayload
+	SmsReceivedPayload      = smsgateway.SmsReceivedPayload
+	SmsDataReceivedPayload  = smsgateway.SmsDataReceivedPayload
+	SmsSentPayload          = smsgateway.SmsSentPayload
+	SmsDeliveredPayload     = smsgateway.SmsDeliveredPayload
+	SmsFailedPayload        = smsgateway.SmsFailedPayload
+	SystemPingPayload       = smsgateway.SystemPingPayload
+	MmsReceivedPayload      = smsgateway.MmsReceivedPayload
+	MmsDownloadedPayload    = smsgateway.MmsDownloadedPayload
+	MmsDownloadedAttachment = smsgateway.MmsDownloadedAttachment
+	AppStartedPayload       = smsgateway.AppStartedPayload
+)
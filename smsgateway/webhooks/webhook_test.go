@@ -18,6 +18,11 @@ func TestIsValidEventType(t *testing.T) {
 			e:    webhooks.EventTypeSmsReceived,
 			want: true,
 		},
+		{
+			name: "Valid MMS event type",
+			e:    webhooks.EventTypeMmsDownloaded,
+			want: true,
+		},
 		{
 			name: "Invalid event type",
 			e:    "invalid:event",
@@ -32,3 +37,27 @@ func TestIsValidEventType(t *testing.T) {
 		})
 	}
 }
+
+// TestEventTypes tests that every event type has a constant.
+func TestEventTypes(t *testing.T) {
+	constants := map[webhooks.EventType]bool{
+		webhooks.EventTypeSmsReceived:     true,
+		webhooks.EventTypeSmsDataReceived: true,
+		webhooks.EventTypeSmsSent:         true,
+		webhooks.EventTypeSmsDelivered:    true,
+		webhooks.EventTypeSmsFailed:       true,
+		webhooks.EventTypeSystemPing:      true,
+		webhooks.EventTypeMmsReceived:     true,
+		webhooks.EventTypeMmsDownloaded:   true,
+		webhooks.EventTypeAppStarted:      true,
+	}
+
+	for _, e := range webhooks.EventTypes() {
+		if !constants[e] {
+			t.Errorf("missing constant for event type %q", e)
+		}
+	}
+	if len(webhooks.EventTypes()) != len(constants) {
+		t.Errorf("expected %d event types, got %d", len(constants), len(webhooks.EventTypes()))
+	}
+}